@@ -0,0 +1,255 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/idena-network/idena-contract-runner/codec"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/pkg/errors"
+	"strconv"
+	"sync"
+)
+
+// ContractAbi describes the interface of a contract, types of arguments and results are formats accepted by
+// DynamicArg
+type ContractAbi struct {
+	Name    string       `json:"name"`
+	Methods []*AbiMethod `json:"methods"`
+	Events  []*AbiEvent  `json:"events"`
+}
+
+type AbiMethod struct {
+	Name    string      `json:"name"`
+	Args    []*AbiParam `json:"args"`
+	Returns string      `json:"returns"`
+}
+
+type AbiEvent struct {
+	Name string      `json:"name"`
+	Args []*AbiParam `json:"args"`
+}
+
+type AbiParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type NamedArgs map[string]json.RawMessage
+
+type AbiRegistry struct {
+	byContract map[common.Address]*ContractAbi
+	byCodeHash map[common.Hash]*ContractAbi
+	mutex      sync.RWMutex
+}
+
+func NewAbiRegistry() *AbiRegistry {
+	return &AbiRegistry{
+		byContract: make(map[common.Address]*ContractAbi),
+		byCodeHash: make(map[common.Hash]*ContractAbi),
+	}
+}
+
+func (r *AbiRegistry) RegisterContract(contract common.Address, abi *ContractAbi) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.byContract[contract] = abi
+}
+
+func (r *AbiRegistry) RegisterCodeHash(codeHash common.Hash, abi *ContractAbi) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.byCodeHash[codeHash] = abi
+}
+
+// Get returns the abi registered for the contract address or, if there is none, for the code hash of the contract
+func (r *AbiRegistry) Get(contract common.Address, appState *appstate.AppState) *ContractAbi {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if abi, ok := r.byContract[contract]; ok {
+		return abi
+	}
+	if len(r.byCodeHash) == 0 || appState == nil {
+		return nil
+	}
+	if codeHash := appState.State.GetCodeHash(contract); codeHash != nil {
+		return r.byCodeHash[*codeHash]
+	}
+	return nil
+}
+
+// validate checks the types of method args and results and of event args
+func (abi *ContractAbi) validate() error {
+	for _, m := range abi.Methods {
+		if m == nil {
+			return errors.New("abi method is empty")
+		}
+		if err := validateParams(m.Args); err != nil {
+			return errors.Wrapf(err, "method \"%v\"", m.Name)
+		}
+		if m.Returns != "" {
+			if err := codec.Validate(m.Returns); err != nil {
+				return errors.Wrapf(err, "method \"%v\" returns", m.Name)
+			}
+		}
+	}
+	for _, e := range abi.Events {
+		if e == nil {
+			return errors.New("abi event is empty")
+		}
+		if err := validateParams(e.Args); err != nil {
+			return errors.Wrapf(err, "event \"%v\"", e.Name)
+		}
+	}
+	return nil
+}
+
+func validateParams(params []*AbiParam) error {
+	for _, param := range params {
+		if param == nil {
+			return errors.New("arg is empty")
+		}
+		if err := codec.Validate(param.Type); err != nil {
+			return errors.Wrapf(err, "arg \"%v\"", param.Name)
+		}
+	}
+	return nil
+}
+
+func (abi *ContractAbi) Method(name string) *AbiMethod {
+	for _, m := range abi.Methods {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+func (abi *ContractAbi) Event(name string) *AbiEvent {
+	for _, e := range abi.Events {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// ToDynamicArgs orders named arguments as declared by the method
+func (m *AbiMethod) ToDynamicArgs(args NamedArgs) (DynamicArgs, error) {
+	var result DynamicArgs
+	for i, param := range m.Args {
+		raw, ok := args[param.Name]
+		if !ok {
+			return nil, errors.Errorf("missing argument \"%v\" of method \"%v\"", param.Name, m.Name)
+		}
+		result = append(result, &DynamicArg{Index: i, Format: param.Type, Value: codec.RawValue(raw)})
+	}
+	for name := range args {
+		if !m.hasArg(name) {
+			return nil, errors.Errorf("unknown argument \"%v\" of method \"%v\"", name, m.Name)
+		}
+	}
+	return result, nil
+}
+
+func (m *AbiMethod) hasArg(name string) bool {
+	for _, param := range m.Args {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode converts event args to a map of named values, args without declared type are returned as hex
+func (e *AbiEvent) Decode(args [][]byte) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for i, data := range args {
+		name := "arg" + strconv.Itoa(i)
+		format := "hex"
+		if i < len(e.Args) {
+			name, format = e.Args[i].Name, e.Args[i].Type
+		}
+		value, err := codec.Decode(format, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "event \"%v\", arg \"%v\"", e.Name, name)
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+// resolveArgs returns the dynamic args of the call, named args are converted using the abi of the contract in the
+// state the call runs against
+func (api *BaseApi) resolveArgs(appState *appstate.AppState, contract common.Address, method string, args DynamicArgs, namedArgs NamedArgs) (DynamicArgs, error) {
+	if namedArgs == nil {
+		return args, nil
+	}
+	if len(args) > 0 {
+		return nil, errors.New("args and namedArgs can't be specified at the same time")
+	}
+	abi := api.abi.Get(contract, appState)
+	if abi == nil {
+		return nil, errors.Errorf("abi of contract %v is not registered", contract.Hex())
+	}
+	m := abi.Method(method)
+	if m == nil {
+		return nil, errors.Errorf("method \"%v\" is not declared in abi of contract %v", method, contract.Hex())
+	}
+	return m.ToDynamicArgs(namedArgs)
+}
+
+// decodeReceipt fills decoded output and event values of the receipt using registered abis
+func (api *BaseApi) decodeReceipt(receipt *TxReceipt, appState *appstate.AppState) {
+	if receipt == nil {
+		return
+	}
+	if abi := api.abi.Get(receipt.Contract, appState); abi != nil && receipt.ActionResult != nil {
+		if m := abi.Method(receipt.Method); m != nil && m.Returns != "" && len(receipt.ActionResult.OutputData) > 0 {
+			receipt.Output, _ = codec.Decode(m.Returns, receipt.ActionResult.OutputData)
+		}
+	}
+	for i := range receipt.Events {
+		api.decodeEvent(&receipt.Events[i], appState)
+	}
+}
+
+// decodeEvent fills decoded values of the event if the abi of the contract declares it
+func (api *BaseApi) decodeEvent(event *Event, appState *appstate.AppState) {
+	abi := api.abi.Get(event.Contract, appState)
+	if abi == nil {
+		return
+	}
+	if e := abi.Event(event.Event); e != nil {
+		event.Values, _ = e.Decode(event.rawArgs())
+	}
+}
+
+type RegisterAbiArgs struct {
+	Contract *common.Address `json:"contract"`
+	CodeHash *common.Hash    `json:"codeHash"`
+	Abi      *ContractAbi    `json:"abi"`
+}
+
+// RegisterAbi registers the abi for the contract address or for all contracts with the code hash, types of the abi
+// must be formats of the codec
+func (api *ContractApi) RegisterAbi(args RegisterAbiArgs) error {
+	if args.Abi == nil {
+		return errors.New("abi is empty")
+	}
+	if err := args.Abi.validate(); err != nil {
+		return err
+	}
+	if (args.Contract == nil) == (args.CodeHash == nil) {
+		return errors.New("either contract or codeHash should be specified")
+	}
+	if args.Contract != nil {
+		api.baseApi.abi.RegisterContract(*args.Contract, args.Abi)
+	} else {
+		api.baseApi.abi.RegisterCodeHash(*args.CodeHash, args.Abi)
+	}
+	return nil
+}
+
+func (api *ContractApi) GetAbi(contract common.Address) *ContractAbi {
+	return api.baseApi.abi.Get(contract, api.baseApi.getReadonlyAppState())
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/idena-network/idena-go/ipfs"
 	"github.com/idena-network/idena-go/keystore"
 	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/rpc"
 	"github.com/idena-network/idena-go/secstore"
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 )
 
@@ -22,6 +24,7 @@ type BaseApi struct {
 	secStore *secstore.SecStore
 	txpool   *mempool.TxPool
 	ipfs     ipfs.Proxy
+	abi      *AbiRegistry
 }
 
 type BaseTxArgs struct {
@@ -30,7 +33,7 @@ type BaseTxArgs struct {
 }
 
 func NewBaseApi(chain *chain.MemBlockchain, ks *keystore.KeyStore, secStore *secstore.SecStore, ipfs ipfs.Proxy, txpool *mempool.TxPool) *BaseApi {
-	return &BaseApi{chain, ks, secStore, txpool, ipfs}
+	return &BaseApi{chain, ks, secStore, txpool, ipfs, NewAbiRegistry()}
 }
 
 func (api *BaseApi) getReadonlyAppState() *appstate.AppState {
@@ -41,6 +44,31 @@ func (api *BaseApi) getReadonlyAppState() *appstate.AppState {
 	return state
 }
 
+// blockContracts looks contracts up in the state of the block and, for contracts terminated in the block, in the state
+// of its parent. It returns nil if both states are pruned.
+func (api *BaseApi) blockContracts(height uint64) contractLookup {
+	var states []*appstate.AppState
+	for _, h := range []uint64{height, height - 1} {
+		if h == 0 || h > height {
+			continue
+		}
+		if appState, err := api.chain.ReadonlyAppStateAt(h); err == nil {
+			states = append(states, appState)
+		}
+	}
+	if len(states) == 0 {
+		return nil
+	}
+	return func(contract common.Address) ([]byte, bool) {
+		for _, appState := range states {
+			if code, deployed := stateContracts(appState)(contract); deployed {
+				return code, true
+			}
+		}
+		return nil, false
+	}
+}
+
 func (api *BaseApi) getAppStateForCheck() *appstate.AppState {
 	state, err := api.chain.AppStateForCheck()
 	if err != nil {
@@ -49,6 +77,70 @@ func (api *BaseApi) getAppStateForCheck() *appstate.AppState {
 	return state
 }
 
+// getAppStateAt returns the state and the head to run reads against: the committed head state for "latest",
+// the check state for "pending" and the committed state of the block for a block number.
+func (api *BaseApi) getAppStateAt(blockNumber rpc.BlockNumber) (*appstate.AppState, *types.Header, error) {
+	switch blockNumber {
+	case rpc.LatestBlockNumber:
+		return api.getReadonlyAppState(), api.chain.Head, nil
+	case rpc.PendingBlockNumber:
+		return api.getAppStateForCheck(), api.chain.Head, nil
+	}
+	header, err := api.getHeader(blockNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Height() == api.chain.Head.Height() {
+		return api.getReadonlyAppState(), header, nil
+	}
+	state, err := api.chain.ReadonlyAppStateAt(header.Height())
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "state of block %v is not available", header.Height())
+	}
+	return state, header, nil
+}
+
+// getAppStateForCheckAt is the same as getAppStateAt but returns a copy of the state that can be modified
+func (api *BaseApi) getAppStateForCheckAt(blockNumber rpc.BlockNumber) (*appstate.AppState, *types.Header, error) {
+	switch blockNumber {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		return api.getAppStateForCheck(), api.chain.Head, nil
+	}
+	header, err := api.getHeader(blockNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := api.chain.AppStateForCheckAt(header.Height())
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "state of block %v is not available", header.Height())
+	}
+	return state, header, nil
+}
+
+func (api *BaseApi) getHeader(blockNumber rpc.BlockNumber) (*types.Header, error) {
+	height := uint64(blockNumber)
+	header := api.chain.GetBlockHeaderByHeight(height)
+	if header == nil {
+		return nil, errors.Errorf("block %v not found", height)
+	}
+	return header, nil
+}
+
+// blockHeight converts the block number to a height, "latest" and "pending" both mean the current head
+func (api *BaseApi) blockHeight(blockNumber rpc.BlockNumber) uint64 {
+	if blockNumber < 0 {
+		return api.chain.Head.Height()
+	}
+	return uint64(blockNumber)
+}
+
+func blockNumberOrDefault(blockNumber *rpc.BlockNumber, defaultValue rpc.BlockNumber) rpc.BlockNumber {
+	if blockNumber == nil {
+		return defaultValue
+	}
+	return *blockNumber
+}
+
 func (api *BaseApi) getCurrentCoinbase() common.Address {
 	return api.secStore.GetAddress()
 }
@@ -86,7 +178,8 @@ func (api *BaseApi) sendInternalTx(ctx context.Context, tx *types.Transaction) (
 	log.Info("Sending new tx", "ip", ctx.Value("remote"), "type", tx.Type, "hash", tx.Hash().Hex(), "nonce", tx.AccountNonce, "epoch", tx.Epoch)
 
 	if err := api.txpool.AddInternalTx(tx); err != nil {
-		return common.Hash{}, err
+		api.chain.TxJournal().Reject(tx, err, api.chain.Head.Height())
+		return common.Hash{}, txRejectedError(ctx, err)
 	}
 
 	return tx.Hash(), nil
@@ -99,6 +192,9 @@ func (api *BaseApi) signTransaction(from common.Address, tx *types.Transaction,
 	if from == api.getCurrentCoinbase() {
 		return api.secStore.SignTx(tx)
 	}
+	if key := api.chain.Key(from); key != nil {
+		return types.SignTx(tx, key)
+	}
 	account, err := api.ks.Find(keystore.Account{Address: from})
 	if err != nil {
 		return nil, err
@@ -112,7 +208,7 @@ func (api *BaseApi) getCoinbaseShard() common.ShardId {
 }
 
 func (api *BaseApi) canSign(address common.Address) bool {
-	if address == api.getCurrentCoinbase() {
+	if address == api.getCurrentCoinbase() || api.chain.Key(address) != nil {
 		return true
 	}
 	_, err := api.ks.Find(keystore.Account{Address: address})
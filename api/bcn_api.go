@@ -0,0 +1,276 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/fee"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/idena-network/idena-go/rlp"
+	"github.com/ipfs/go-cid"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"sort"
+)
+
+// BcnApi mirrors the bcn namespace of idena-go, blocks and transactions have the idena-go json shape
+type BcnApi struct {
+	baseApi  *BaseApi
+	bc       *chain.MemBlockchain
+	pool     *mempool.TxPool
+	chainApi *ChainApi
+}
+
+func NewBcnApi(baseApi *BaseApi, chain *chain.MemBlockchain, pool *mempool.TxPool) *BcnApi {
+	return &BcnApi{
+		baseApi:  baseApi,
+		bc:       chain,
+		pool:     pool,
+		chainApi: NewChainApi(baseApi, chain, pool),
+	}
+}
+
+type BcnBlock struct {
+	Coinbase     common.Address  `json:"coinbase"`
+	Hash         common.Hash     `json:"hash"`
+	ParentHash   common.Hash     `json:"parentHash"`
+	Height       uint64          `json:"height"`
+	Time         int64           `json:"timestamp"`
+	Root         common.Hash     `json:"root"`
+	IdentityRoot common.Hash     `json:"identityRoot"`
+	IpfsHash     *string         `json:"ipfsCid"`
+	Transactions []common.Hash   `json:"transactions"`
+	Flags        []string        `json:"flags"`
+	IsEmpty      bool            `json:"isEmpty"`
+	OfflineAddr  *common.Address `json:"offlineAddress"`
+}
+
+type BcnTransaction struct {
+	Hash      common.Hash     `json:"hash"`
+	Type      string          `json:"type"`
+	From      common.Address  `json:"from"`
+	To        *common.Address `json:"to"`
+	Amount    decimal.Decimal `json:"amount"`
+	Tips      decimal.Decimal `json:"tips"`
+	MaxFee    decimal.Decimal `json:"maxFee"`
+	Nonce     uint32          `json:"nonce"`
+	Epoch     uint16          `json:"epoch"`
+	Payload   hexutil.Bytes   `json:"payload"`
+	BlockHash common.Hash     `json:"blockHash"`
+	UsedFee   decimal.Decimal `json:"usedFee"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+type TransactionsArgs struct {
+	Address common.Address `json:"address"`
+	Count   int            `json:"count"`
+	Token   hexutil.Bytes  `json:"token"`
+}
+
+type BcnTransactions struct {
+	Transactions []*BcnTransaction `json:"transactions"`
+	Token        *hexutil.Bytes    `json:"token"`
+}
+
+type Syncing struct {
+	Syncing      bool   `json:"syncing"`
+	CurrentBlock uint64 `json:"currentBlock"`
+	HighestBlock uint64 `json:"highestBlock"`
+	WrongTime    bool   `json:"wrongTime"`
+	GenesisBlock uint64 `json:"genesisBlock"`
+	Message      string `json:"message"`
+}
+
+type EstimateTxResponse struct {
+	TxHash common.Hash     `json:"txHash"`
+	TxFee  decimal.Decimal `json:"txFee"`
+}
+
+func convertBcnBlock(block *types.Block) *BcnBlock {
+	if block == nil {
+		return nil
+	}
+	result := &BcnBlock{
+		IsEmpty:      block.IsEmpty(),
+		Hash:         block.Hash(),
+		IdentityRoot: block.IdentityRoot(),
+		Root:         block.Root(),
+		Height:       block.Height(),
+		ParentHash:   block.Header.ParentHash(),
+		Time:         block.Header.Time(),
+		OfflineAddr:  block.Header.OfflineAddr(),
+	}
+	if !block.IsEmpty() {
+		result.Coinbase = block.Header.Coinbase()
+	}
+	if ipfsHash := block.Header.IpfsHash(); len(ipfsHash) > 0 {
+		c, _ := cid.Parse(ipfsHash)
+		s := c.String()
+		result.IpfsHash = &s
+	}
+	if block.Body != nil {
+		for _, tx := range block.Body.Transactions {
+			result.Transactions = append(result.Transactions, tx.Hash())
+		}
+	}
+	for _, f := range blockFlags {
+		if block.Header.Flags().HasFlag(f.flag) {
+			result.Flags = append(result.Flags, f.name)
+		}
+	}
+	return result
+}
+
+func convertBcnTransaction(tx *types.Transaction, blockHash common.Hash, feePerGas *big.Int, timestamp int64) *BcnTransaction {
+	sender, _ := types.Sender(tx)
+	return &BcnTransaction{
+		Hash:      tx.Hash(),
+		Type:      TxTypeName(tx.Type),
+		From:      sender,
+		To:        tx.To,
+		Amount:    blockchain.ConvertToFloat(tx.Amount),
+		Tips:      blockchain.ConvertToFloat(tx.Tips),
+		MaxFee:    blockchain.ConvertToFloat(tx.MaxFee),
+		Nonce:     tx.AccountNonce,
+		Epoch:     tx.Epoch,
+		Payload:   tx.Payload,
+		BlockHash: blockHash,
+		UsedFee:   blockchain.ConvertToFloat(fee.CalculateFee(1, feePerGas, tx)),
+		Timestamp: timestamp,
+	}
+}
+
+func (api *BcnApi) LastBlock() *BcnBlock {
+	return api.BlockAt(api.bc.Head.Height())
+}
+
+func (api *BcnApi) BlockAt(height uint64) *BcnBlock {
+	return convertBcnBlock(api.bc.GetBlockByHeight(height))
+}
+
+func (api *BcnApi) Block(hash common.Hash) *BcnBlock {
+	return convertBcnBlock(api.bc.GetBlock(hash))
+}
+
+func (api *BcnApi) Transaction(hash common.Hash) *BcnTransaction {
+	if tx := api.pool.GetTx(hash); tx != nil {
+		return convertBcnTransaction(tx, common.Hash{}, nil, 0)
+	}
+	return api.minedTransaction(hash)
+}
+
+func (api *BcnApi) minedTransaction(hash common.Hash) *BcnTransaction {
+	tx, idx := api.bc.GetTx(hash)
+	if tx == nil || idx == nil {
+		return nil
+	}
+	block := api.bc.GetBlock(idx.BlockHash)
+	if block == nil {
+		return nil
+	}
+	return convertBcnTransaction(tx, idx.BlockHash, block.Header.FeePerGas(), block.Header.Time())
+}
+
+func (api *BcnApi) TxReceipt(hash common.Hash) *TxReceipt {
+	return api.chainApi.TxReceipt(hash)
+}
+
+func (api *BcnApi) Mempool() []common.Hash {
+	var txs []common.Hash
+	for _, tx := range api.pool.GetPendingTransaction(true, true, common.MultiShard, false) {
+		txs = append(txs, tx.Hash())
+	}
+	return txs
+}
+
+// Syncing always reports a synchronized node, the runner has no peers
+func (api *BcnApi) Syncing() Syncing {
+	head := api.bc.Head.Height()
+	return Syncing{
+		CurrentBlock: head,
+		HighestBlock: head,
+		GenesisBlock: api.bc.GenesisInfo().Genesis.Height(),
+	}
+}
+
+func (api *BcnApi) FeePerGas() *big.Int {
+	return api.baseApi.getReadonlyAppState().State.FeePerGas()
+}
+
+func (api *BcnApi) SendRawTx(ctx context.Context, bytesTx hexutil.Bytes) (common.Hash, error) {
+	var tx types.Transaction
+	if err := tx.FromBytes(bytesTx); err != nil {
+		if err := rlp.DecodeBytes(bytesTx, &tx); err != nil {
+			return common.Hash{}, err
+		}
+		tx.UseRlp = true
+	}
+	return api.baseApi.sendInternalTx(ctx, &tx)
+}
+
+func (api *BcnApi) GetRawTx(args SendTxArgs) (hexutil.Bytes, error) {
+	var payload []byte
+	if args.Payload != nil {
+		payload = *args.Payload
+	}
+	tx := api.baseApi.getTx(args.From, args.To, args.Type, args.Amount, args.MaxFee, args.Tips, args.Nonce, args.Epoch, payload)
+	if args.UseProto {
+		return tx.ToBytes()
+	}
+	return rlp.EncodeToBytes(tx)
+}
+
+func (api *BcnApi) EstimateTx(ctx context.Context, args SendTxArgs) (*EstimateTxResponse, error) {
+	if args.UseProto {
+		return nil, errUseProtoNotSupported
+	}
+	var payload []byte
+	if args.Payload != nil {
+		payload = *args.Payload
+	}
+	tx, err := api.baseApi.getSignedTx(args.From, args.To, args.Type, args.Amount, args.MaxFee, args.Tips, args.Nonce, args.Epoch, payload, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.pool.Validate(tx); err != nil {
+		return nil, txRejectedError(ctx, err)
+	}
+	return &EstimateTxResponse{
+		TxHash: tx.Hash(),
+		TxFee:  blockchain.ConvertToFloat(fee.CalculateFee(1, api.baseApi.getReadonlyAppState().State.FeePerGas(), tx)),
+	}, nil
+}
+
+// Transactions returns mined transactions of the address, the newest come first
+func (api *BcnApi) Transactions(args TransactionsArgs) (*BcnTransactions, error) {
+	txs, token, err := addressTxsPage(api.bc, args.Address, args.Count, &args.Token)
+	if err != nil {
+		return nil, err
+	}
+	result := &BcnTransactions{Token: token}
+	for _, item := range txs {
+		if tx := api.minedTransaction(item.Hash); tx != nil {
+			result.Transactions = append(result.Transactions, tx)
+		}
+	}
+	return result, nil
+}
+
+// PendingTransactions returns mempool transactions of the address sorted by epoch and nonce, the newest come first
+func (api *BcnApi) PendingTransactions(args TransactionsArgs) BcnTransactions {
+	txs := api.pool.GetPendingByAddress(args.Address)
+	sort.SliceStable(txs, func(i, j int) bool {
+		if txs[i].Epoch != txs[j].Epoch {
+			return txs[i].Epoch > txs[j].Epoch
+		}
+		return txs[i].AccountNonce > txs[j].AccountNonce
+	})
+	var result BcnTransactions
+	for _, tx := range txs {
+		result.Transactions = append(result.Transactions, convertBcnTransaction(tx, common.Hash{}, nil, 0))
+	}
+	return result
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/idena-network/idena-go/core/mempool"
 	"github.com/idena-network/idena-go/core/state"
 	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/rpc"
 	"github.com/shopspring/decimal"
 	"math/big"
 )
@@ -51,11 +52,13 @@ func (api *ChainApi) TxReceipt(hash common.Hash) *TxReceipt {
 
 	var blockHash common.Hash
 	var feePerGas *big.Int
+	var contracts contractLookup
 	if idx != nil {
 		blockHash = idx.BlockHash
 		block := api.bc.GetBlock(blockHash)
 		if block != nil {
 			feePerGas = block.Header.FeePerGas()
+			contracts = api.baseApi.blockContracts(block.Height())
 		}
 	}
 
@@ -63,7 +66,10 @@ func (api *ChainApi) TxReceipt(hash common.Hash) *TxReceipt {
 	if receipt == nil {
 		return nil
 	}
-	return convertReceipt(tx, receipt, feePerGas)
+	result := convertReceipt(tx, receipt, feePerGas, contracts)
+	result.DebugOutput = api.bc.DebugOutputs().Get(hash)
+	api.baseApi.decodeReceipt(result, api.baseApi.getReadonlyAppState())
+	return result
 }
 
 func (api *ChainApi) ResetTo(block uint64) error {
@@ -76,17 +82,22 @@ func (api *ChainApi) ResetTo(block uint64) error {
 	return nil
 }
 
+// SetIdentity sets the identity state of the address, the state takes effect at the next generated block
 func (api *ChainApi) SetIdentity(addr common.Address, status state.IdentityState) {
 	api.bc.SetIdentity(addr, status)
 }
 
+// AddBalance credits the address, the balance takes effect at the next generated block
 func (api *ChainApi) AddBalance(addr common.Address, amount decimal.Decimal) {
 	api.bc.AddBalance(addr, amount)
 }
 
-func (api *ChainApi) GetBalance(addr common.Address) decimal.Decimal {
-	state := api.baseApi.getAppStateForCheck()
-	return blockchain.ConvertToFloat(state.State.GetBalance(addr))
+func (api *ChainApi) GetBalance(addr common.Address, blockNumber *rpc.BlockNumber) (decimal.Decimal, error) {
+	state, _, err := api.baseApi.getAppStateAt(blockNumberOrDefault(blockNumber, rpc.PendingBlockNumber))
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return blockchain.ConvertToFloat(state.State.GetBalance(addr)), nil
 }
 
 func (api *ChainApi) SetContractData(addr common.Address, key string, value string, format string) error {
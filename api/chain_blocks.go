@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+const maxBlocksRange = 1000
+
+type Block struct {
+	Height     uint64      `json:"height"`
+	Hash       common.Hash `json:"hash"`
+	ParentHash common.Hash `json:"parentHash"`
+	Time       int64       `json:"timestamp"`
+	// proposer is empty for empty blocks
+	Proposer     *common.Address  `json:"proposer"`
+	FeePerGas    *decimal.Decimal `json:"feePerGas"`
+	GasUsed      uint64           `json:"gasUsed"`
+	Root         common.Hash      `json:"root"`
+	Flags        []string         `json:"flags"`
+	IsEmpty      bool             `json:"isEmpty"`
+	Transactions []common.Hash    `json:"transactions"`
+}
+
+var blockFlags = []struct {
+	flag types.BlockFlag
+	name string
+}{
+	{types.IdentityUpdate, "IdentityUpdate"},
+	{types.FlipLotteryStarted, "FlipLotteryStarted"},
+	{types.ShortSessionStarted, "ShortSessionStarted"},
+	{types.LongSessionStarted, "LongSessionStarted"},
+	{types.AfterLongSessionStarted, "AfterLongSessionStarted"},
+	{types.ValidationFinished, "ValidationFinished"},
+	{types.Snapshot, "Snapshot"},
+	{types.OfflinePropose, "OfflinePropose"},
+	{types.OfflineCommit, "OfflineCommit"},
+	{types.NewGenesis, "NewGenesis"},
+}
+
+func (api *ChainApi) convertBlock(block *types.Block) *Block {
+	if block == nil {
+		return nil
+	}
+	result := &Block{
+		Height:     block.Height(),
+		Hash:       block.Hash(),
+		ParentHash: block.Header.ParentHash(),
+		Time:       block.Header.Time(),
+		Root:       block.Root(),
+		IsEmpty:    block.IsEmpty(),
+	}
+	if !block.IsEmpty() {
+		proposer := block.Header.Coinbase()
+		result.Proposer = &proposer
+	}
+	if feePerGas := block.Header.FeePerGas(); feePerGas != nil {
+		fee := blockchain.ConvertToFloat(feePerGas)
+		result.FeePerGas = &fee
+	}
+	for _, f := range blockFlags {
+		if block.Header.Flags().HasFlag(f.flag) {
+			result.Flags = append(result.Flags, f.name)
+		}
+	}
+	if block.Body != nil {
+		for _, tx := range block.Body.Transactions {
+			result.Transactions = append(result.Transactions, tx.Hash())
+			if receipt := api.bc.GetReceipt(tx.Hash()); receipt != nil {
+				result.GasUsed += receipt.GasUsed
+			}
+		}
+	}
+	return result
+}
+
+func (api *ChainApi) Head() *Block {
+	return api.convertBlock(api.bc.GetBlockByHeight(api.bc.Head.Height()))
+}
+
+func (api *ChainApi) GetBlockByHeight(height uint64) *Block {
+	return api.convertBlock(api.bc.GetBlockByHeight(height))
+}
+
+func (api *ChainApi) GetBlockByHash(hash common.Hash) *Block {
+	return api.convertBlock(api.bc.GetBlock(hash))
+}
+
+// GetBlocks returns blocks from the inclusive range, the range is truncated by the head block
+func (api *ChainApi) GetBlocks(from uint64, to uint64) ([]*Block, error) {
+	if from > to {
+		return nil, errors.New("from should not be greater than to")
+	}
+	if to-from >= maxBlocksRange {
+		return nil, errors.Errorf("range should not exceed %v blocks", maxBlocksRange)
+	}
+	if head := api.bc.Head.Height(); to > head {
+		to = head
+	}
+	var result []*Block
+	for height := from; height <= to; height++ {
+		if block := api.convertBlock(api.bc.GetBlockByHeight(height)); block != nil {
+			result = append(result, block)
+		}
+	}
+	return result, nil
+}
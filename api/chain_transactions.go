@@ -0,0 +1,235 @@
+package api
+
+import (
+	"encoding/binary"
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/attachments"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	TxStatusPending = "pending"
+	TxStatusMined   = "mined"
+	TxStatusFailed  = "failed"
+
+	defaultTxsLimit = 100
+	txsTokenLength  = 12
+)
+
+var txTypeNames = map[types.TxType]string{
+	types.SendTx:               "send",
+	types.ActivationTx:         "activation",
+	types.InviteTx:             "invite",
+	types.KillTx:               "kill",
+	types.KillInviteeTx:        "killInvitee",
+	types.SubmitFlipTx:         "submitFlip",
+	types.SubmitAnswersHashTx:  "submitAnswersHash",
+	types.SubmitShortAnswersTx: "submitShortAnswers",
+	types.SubmitLongAnswersTx:  "submitLongAnswers",
+	types.EvidenceTx:           "evidence",
+	types.OnlineStatusTx:       "online",
+	types.ChangeGodAddressTx:   "changeGodAddress",
+	types.BurnTx:               "burn",
+	types.ChangeProfileTx:      "changeProfile",
+	types.DeleteFlipTx:         "deleteFlip",
+	types.DeployContractTx:     "deployContract",
+	types.CallContractTx:       "callContract",
+	types.TerminateContractTx:  "terminateContract",
+	types.DelegateTx:           "delegate",
+	types.UndelegateTx:         "undelegate",
+	types.KillDelegatorTx:      "killDelegator",
+	types.StoreToIpfsTx:        "storeToIpfs",
+	types.ReplenishStakeTx:     "replenishStake",
+}
+
+// TxTypeName returns the name of the tx type used in api responses
+func TxTypeName(txType types.TxType) string {
+	if name, ok := txTypeNames[txType]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+type Transaction struct {
+	Hash       common.Hash     `json:"hash"`
+	Type       string          `json:"type"`
+	From       common.Address  `json:"from"`
+	To         *common.Address `json:"to"`
+	Amount     decimal.Decimal `json:"amount"`
+	MaxFee     decimal.Decimal `json:"maxFee"`
+	Tips       decimal.Decimal `json:"tips"`
+	Nonce      uint32          `json:"nonce"`
+	Epoch      uint16          `json:"epoch"`
+	Payload    hexutil.Bytes   `json:"payload"`
+	Attachment *TxAttachment   `json:"attachment,omitempty"`
+	Status     string          `json:"status"`
+	// position of mined transactions
+	BlockHeight *uint64      `json:"blockHeight,omitempty"`
+	BlockHash   *common.Hash `json:"blockHash,omitempty"`
+	TxIndex     *uint32      `json:"txIndex,omitempty"`
+	Timestamp   *int64       `json:"timestamp,omitempty"`
+}
+
+// TxAttachment is a decoded payload of contract transactions
+type TxAttachment struct {
+	Method   string          `json:"method,omitempty"`
+	CodeHash *common.Hash    `json:"codeHash,omitempty"`
+	CodeSize int             `json:"codeSize,omitempty"`
+	Nonce    hexutil.Bytes   `json:"nonce,omitempty"`
+	Args     []hexutil.Bytes `json:"args"`
+}
+
+type GetTransactionsResponse struct {
+	Transactions      []*Transaction `json:"transactions"`
+	ContinuationToken *hexutil.Bytes `json:"continuationToken"`
+}
+
+func convertAttachment(tx *types.Transaction) *TxAttachment {
+	convertArgs := func(args [][]byte) []hexutil.Bytes {
+		result := make([]hexutil.Bytes, 0, len(args))
+		for _, arg := range args {
+			result = append(result, arg)
+		}
+		return result
+	}
+	switch tx.Type {
+	case types.DeployContractTx:
+		if a := attachments.ParseDeployContractAttachment(tx); a != nil {
+			return &TxAttachment{CodeHash: &a.CodeHash, CodeSize: len(a.Code), Nonce: a.Nonce, Args: convertArgs(a.Args)}
+		}
+	case types.CallContractTx:
+		if a := attachments.ParseCallContractAttachment(tx); a != nil {
+			return &TxAttachment{Method: a.Method, Args: convertArgs(a.Args)}
+		}
+	case types.TerminateContractTx:
+		if a := attachments.ParseTerminateContractAttachment(tx); a != nil {
+			return &TxAttachment{Args: convertArgs(a.Args)}
+		}
+	}
+	return nil
+}
+
+func convertTransaction(tx *types.Transaction) *Transaction {
+	sender, _ := types.Sender(tx)
+	return &Transaction{
+		Hash:       tx.Hash(),
+		Type:       TxTypeName(tx.Type),
+		From:       sender,
+		To:         tx.To,
+		Amount:     blockchain.ConvertToFloat(tx.Amount),
+		MaxFee:     blockchain.ConvertToFloat(tx.MaxFee),
+		Tips:       blockchain.ConvertToFloat(tx.Tips),
+		Nonce:      tx.AccountNonce,
+		Epoch:      tx.Epoch,
+		Payload:    tx.Payload,
+		Attachment: convertAttachment(tx),
+		Status:     TxStatusPending,
+	}
+}
+
+// minedTransaction returns the transaction with its position, transactions left in the storage after a chain reset
+// are not returned
+func (api *ChainApi) minedTransaction(hash common.Hash) *Transaction {
+	tx, idx := api.bc.GetTx(hash)
+	if tx == nil || idx == nil {
+		return nil
+	}
+	block := api.bc.GetBlock(idx.BlockHash)
+	if block == nil || block.Height() > api.bc.Head.Height() {
+		return nil
+	}
+	if canonical := api.bc.GetBlockByHeight(block.Height()); canonical == nil || canonical.Hash() != block.Hash() {
+		return nil
+	}
+	result := convertTransaction(tx)
+	height, blockHash, txIndex, timestamp := block.Height(), block.Hash(), idx.Idx, block.Header.Time()
+	result.BlockHeight, result.BlockHash, result.TxIndex, result.Timestamp = &height, &blockHash, &txIndex, &timestamp
+	result.Status = TxStatusMined
+	if receipt := api.bc.GetReceipt(hash); receipt != nil && !receipt.Success {
+		result.Status = TxStatusFailed
+	}
+	return result
+}
+
+// GetTransaction returns a pending or a mined transaction, nil for unknown transactions
+func (api *ChainApi) GetTransaction(hash common.Hash) *Transaction {
+	if tx := api.pool.GetTx(hash); tx != nil {
+		return convertTransaction(tx)
+	}
+	return api.minedTransaction(hash)
+}
+
+type txPosition struct {
+	height uint64
+	index  uint32
+}
+
+func (p txPosition) token() hexutil.Bytes {
+	data := make([]byte, txsTokenLength)
+	binary.BigEndian.PutUint64(data, p.height)
+	binary.BigEndian.PutUint32(data[8:], p.index)
+	return data
+}
+
+func parseTxsToken(token hexutil.Bytes) (txPosition, error) {
+	if len(token) != txsTokenLength {
+		return txPosition{}, errors.New("invalid continuation token")
+	}
+	return txPosition{
+		height: binary.BigEndian.Uint64(token),
+		index:  binary.BigEndian.Uint32(token[8:]),
+	}, nil
+}
+
+func (p txPosition) after(other txPosition) bool {
+	return p.height > other.height || p.height == other.height && p.index > other.index
+}
+
+// GetTransactionsByAddress returns mined transactions sent by or to the address, the newest come first
+func (api *ChainApi) GetTransactionsByAddress(address common.Address, limit int, continuationToken *hexutil.Bytes) (*GetTransactionsResponse, error) {
+	txs, token, err := addressTxsPage(api.bc, address, limit, continuationToken)
+	if err != nil {
+		return nil, err
+	}
+	result := &GetTransactionsResponse{ContinuationToken: token}
+	for _, item := range txs {
+		if tx := api.minedTransaction(item.Hash); tx != nil {
+			result.Transactions = append(result.Transactions, tx)
+		}
+	}
+	return result, nil
+}
+
+// addressTxsPage returns a page of indexed transactions of the address starting from the newest one
+func addressTxsPage(bc *chain.MemBlockchain, address common.Address, limit int, continuationToken *hexutil.Bytes) ([]*chain.AddressTx, *hexutil.Bytes, error) {
+	if limit <= 0 {
+		limit = defaultTxsLimit
+	}
+	var start *txPosition
+	if continuationToken != nil && len(*continuationToken) > 0 {
+		position, err := parseTxsToken(*continuationToken)
+		if err != nil {
+			return nil, nil, err
+		}
+		start = &position
+	}
+	txs := bc.TxIndex().Transactions(address, bc.Head.Height())
+	var result []*chain.AddressTx
+	for i := len(txs) - 1; i >= 0; i-- {
+		position := txPosition{txs[i].Height, txs[i].Index}
+		if start != nil && position.after(*start) {
+			continue
+		}
+		if len(result) == limit {
+			token := position.token()
+			return result, &token, nil
+		}
+		result = append(result, txs[i])
+	}
+	return result, nil, nil
+}
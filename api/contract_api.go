@@ -2,8 +2,9 @@ package api
 
 import (
 	"context"
-	"fmt"
 	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-contract-runner/codec"
+	"github.com/idena-network/idena-contract-runner/metrics"
 	"github.com/idena-network/idena-go/blockchain"
 	"github.com/idena-network/idena-go/blockchain/attachments"
 	"github.com/idena-network/idena-go/blockchain/fee"
@@ -11,15 +12,15 @@ import (
 	"github.com/idena-network/idena-go/blockchain/validation"
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/rpc"
 	"github.com/idena-network/idena-go/vm"
 	"github.com/idena-network/idena-go/vm/env"
-	"github.com/idena-network/idena-go/vm/helpers"
 	models "github.com/idena-network/idena-wasm-binding/lib/protobuf"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"google.golang.org/protobuf/proto"
 	"math/big"
-	"strconv"
 )
 
 type ContractApi struct {
@@ -50,6 +51,7 @@ type CallArgs struct {
 	Args           DynamicArgs     `json:"args"`
 	MaxFee         decimal.Decimal `json:"maxFee"`
 	BroadcastBlock uint64          `json:"broadcastBlock"`
+	NamedArgs      NamedArgs       `json:"namedArgs"`
 }
 
 type TerminateArgs struct {
@@ -68,70 +70,29 @@ type DynamicArg struct {
 }
 
 type ReadonlyCallArgs struct {
-	Contract common.Address `json:"contract"`
-	Method   string         `json:"method"`
-	Format   string         `json:"format"`
-	Args     DynamicArgs    `json:"args"`
+	Contract    common.Address   `json:"contract"`
+	Method      string           `json:"method"`
+	Format      string           `json:"format"`
+	Args        DynamicArgs      `json:"args"`
+	NamedArgs   NamedArgs        `json:"namedArgs"`
+	BlockNumber *rpc.BlockNumber `json:"blockNumber"`
+	// Debug wraps the result to return the contract output
+	Debug bool `json:"debug"`
+}
+
+// ReadonlyCallResult is returned by readonly calls with the debug flag
+type ReadonlyCallResult struct {
+	Result      interface{} `json:"result"`
+	DebugOutput []string    `json:"debugOutput"`
 }
 
 type EventsArgs struct {
 	Contract common.Address `json:"contract"`
 }
 
+// ToBytes encodes the value according to the format, see the codec package for the list of supported formats
 func (a DynamicArg) ToBytes() ([]byte, error) {
-	switch a.Format {
-	case "byte":
-		i, err := strconv.ParseUint(a.Value, 10, 8)
-		if err != nil {
-			return nil, errors.Errorf("cannot parse byte: \"%v\"", a.Value)
-		}
-		return []byte{byte(i)}, nil
-	case "int8":
-		i, err := strconv.ParseInt(a.Value, 10, 8)
-		if err != nil {
-			return nil, errors.Errorf("cannot parse int8: \"%v\"", a.Value)
-		}
-		return common.ToBytes(i), nil
-	case "uint64":
-		i, err := strconv.ParseUint(a.Value, 10, 64)
-		if err != nil {
-			return nil, errors.Errorf("cannot parse uint64: \"%v\"", a.Value)
-		}
-		return common.ToBytes(i), nil
-	case "int64":
-		i, err := strconv.ParseInt(a.Value, 10, 64)
-		if err != nil {
-			return nil, errors.Errorf("cannot parse int64: \"%v\"", a.Value)
-		}
-		return common.ToBytes(i), nil
-	case "string":
-		return []byte(a.Value), nil
-	case "bigint":
-		v := new(big.Int)
-		_, ok := v.SetString(a.Value, 10)
-		if !ok {
-			return nil, errors.Errorf("cannot parse bigint: \"%v\"", a.Value)
-		}
-		return v.Bytes(), nil
-	case "hex":
-		data, err := hexutil.Decode(a.Value)
-		if err != nil {
-			return nil, errors.Errorf("cannot parse hex: \"%v\"", a.Value)
-		}
-		return data, nil
-	case "dna":
-		d, err := decimal.NewFromString(a.Value)
-		if err != nil {
-			return nil, errors.Errorf("cannot parse dna: \"%v\"", a.Value)
-		}
-		return blockchain.ConvertToInt(d).Bytes(), nil
-	default:
-		data, err := hexutil.Decode(a.Value)
-		if err != nil {
-			return nil, errors.Errorf("cannot parse hex: \"%v\"", a.Value)
-		}
-		return data, nil
-	}
+	return codec.Encode(a.Format, a.Value)
 }
 
 func (d DynamicArgs) ToSlice() ([][]byte, error) {
@@ -167,10 +128,15 @@ type TxReceipt struct {
 	GasUsed      uint64          `json:"gasUsed"`
 	TxHash       *common.Hash    `json:"txHash"`
 	Error        string          `json:"error"`
+	ErrorCode    int             `json:"errorCode,omitempty"`
+	ErrorData    *ErrorData      `json:"errorData,omitempty"`
 	GasCost      decimal.Decimal `json:"gasCost"`
 	TxFee        decimal.Decimal `json:"txFee"`
 	ActionResult *ActionResult   `json:"actionResult"`
 	Events       []Event         `json:"events"`
+	Output       interface{}     `json:"output,omitempty"`
+	// DebugOutput is printed by the contract with the debug host call
+	DebugOutput []string `json:"debugOutput,omitempty"`
 }
 
 type ActionResult struct {
@@ -193,9 +159,18 @@ type InputAction struct {
 }
 
 type Event struct {
-	Contract common.Address  `json:"contract"`
-	Event    string          `json:"event"`
-	Args     []hexutil.Bytes `json:"args"`
+	Contract common.Address         `json:"contract"`
+	Event    string                 `json:"event"`
+	Args     []hexutil.Bytes        `json:"args"`
+	Values   map[string]interface{} `json:"values,omitempty"`
+}
+
+func (e *Event) rawArgs() [][]byte {
+	args := make([][]byte, len(e.Args))
+	for i := range e.Args {
+		args[i] = e.Args[i]
+	}
+	return args
 }
 
 type MapItem struct {
@@ -206,9 +181,10 @@ type MapItem struct {
 type IterateMapResponse struct {
 	Items             []*MapItem     `json:"items"`
 	ContinuationToken *hexutil.Bytes `json:"continuationToken"`
+	Count             *int           `json:"count,omitempty"`
 }
 
-func (api *ContractApi) buildDeployContractTx(args DeployArgs, estimate bool) (*types.Transaction, error) {
+func (api *ContractApi) buildDeployContractTx(args DeployArgs, nonce uint32, estimate bool) (*types.Transaction, error) {
 	var codeHash common.Hash
 	codeHash.SetBytes(args.CodeHash)
 
@@ -221,27 +197,31 @@ func (api *ContractApi) buildDeployContractTx(args DeployArgs, estimate bool) (*
 		return nil, err
 	}
 	payload, _ := attachments.CreateDeployContractAttachment(codeHash, args.Code, args.Nonce, convertedArgs...).ToBytes()
-	tx := api.baseApi.getTx(from, nil, types.DeployContractTx, args.Amount, args.MaxFee, decimal.Zero, 0, 0, payload)
+	tx := api.baseApi.getTx(from, nil, types.DeployContractTx, args.Amount, args.MaxFee, decimal.Zero, nonce, 0, payload)
 	return api.signIfNeeded(from, tx, estimate)
 }
 
-func (api *ContractApi) buildCallContractTx(args CallArgs, estimate bool) (*types.Transaction, error) {
+func (api *ContractApi) buildCallContractTx(args CallArgs, nonce uint32, estimate bool) (*types.Transaction, error) {
 
 	from := args.From
 	if from == (common.Address{}) {
 		from = api.baseApi.getCurrentCoinbase()
 	}
-	convertedArgs, err := args.Args.ToSlice()
+	dynamicArgs, err := api.baseApi.resolveArgs(api.baseApi.getReadonlyAppState(), args.Contract, args.Method, args.Args, args.NamedArgs)
+	if err != nil {
+		return nil, err
+	}
+	convertedArgs, err := dynamicArgs.ToSlice()
 	if err != nil {
 		return nil, err
 	}
 	payload, _ := attachments.CreateCallContractAttachment(args.Method, convertedArgs...).ToBytes()
-	tx := api.baseApi.getTx(from, &args.Contract, types.CallContractTx, args.Amount, args.MaxFee, decimal.Zero, 0, 0,
+	tx := api.baseApi.getTx(from, &args.Contract, types.CallContractTx, args.Amount, args.MaxFee, decimal.Zero, nonce, 0,
 		payload)
 	return api.signIfNeeded(from, tx, estimate)
 }
 
-func (api *ContractApi) buildTerminateContractTx(args TerminateArgs, estimate bool) (*types.Transaction, error) {
+func (api *ContractApi) buildTerminateContractTx(args TerminateArgs, nonce uint32, estimate bool) (*types.Transaction, error) {
 
 	from := args.From
 	if from == (common.Address{}) {
@@ -252,11 +232,21 @@ func (api *ContractApi) buildTerminateContractTx(args TerminateArgs, estimate bo
 		return nil, err
 	}
 	payload, _ := attachments.CreateTerminateContractAttachment(convertedArgs...).ToBytes()
-	tx := api.baseApi.getTx(from, &args.Contract, types.TerminateContractTx, decimal.Zero, args.MaxFee, decimal.Zero, 0,
+	tx := api.baseApi.getTx(from, &args.Contract, types.TerminateContractTx, decimal.Zero, args.MaxFee, decimal.Zero, nonce,
 		0, payload)
 	return api.signIfNeeded(from, tx, estimate)
 }
 
+func (api *ContractApi) getEstimateAppState(stateOverrides *StateOverrides) (*appstate.AppState, error) {
+	appState := api.baseApi.getAppStateForCheck()
+	if stateOverrides != nil {
+		if err := stateOverrides.Apply(appState); err != nil {
+			return nil, err
+		}
+	}
+	return appState, nil
+}
+
 func (api *ContractApi) signIfNeeded(from common.Address, tx *types.Transaction, estimate bool) (*types.Transaction, error) {
 	sign := !estimate || api.baseApi.canSign(from)
 	if !sign {
@@ -265,37 +255,45 @@ func (api *ContractApi) signIfNeeded(from common.Address, tx *types.Transaction,
 	return api.baseApi.signTransaction(from, tx, nil)
 }
 
-func (api *ContractApi) EstimateDeploy(args DeployArgs) (*TxReceipt, error) {
-	appState := api.baseApi.getAppStateForCheck()
+func (api *ContractApi) EstimateDeploy(ctx context.Context, args DeployArgs, stateOverrides *StateOverrides) (*TxReceipt, error) {
+	appState, err := api.getEstimateAppState(stateOverrides)
+	if err != nil {
+		return nil, err
+	}
 	vm := vm.NewVmImpl(appState, api.bc, api.bc.Head, nil, api.bc.Config())
-	tx, err := api.buildDeployContractTx(args, true)
+	tx, err := api.buildDeployContractTx(args, 0, true)
 	if err != nil {
 		return nil, err
 	}
 	var from *common.Address
 	if tx.Signed() {
 		if err := validation.ValidateTx(appState, tx, appState.State.FeePerGas(), validation.MempoolTx); err != nil {
-			return nil, err
+			return nil, txRejectedError(ctx, err)
 		}
 	} else {
 		from = &args.From
 	}
-	r := vm.Run(tx, from, -1)
+	r, output := runVm(api.bc, vm, appState, tx, from, -1, metrics.VmEstimate)
 	r.GasCost = api.bc.GetGasCost(appState, r.GasUsed)
-	return convertEstimatedReceipt(tx, r, appState.State.FeePerGas()), nil
+	receipt := convertEstimatedReceipt(tx, r, appState.State.FeePerGas(), stateContracts(appState))
+	receipt.DebugOutput = output
+	return receipt, nil
 }
 
-func (api *ContractApi) EstimateCall(args CallArgs) (*TxReceipt, error) {
-	appState := api.baseApi.getAppStateForCheck()
+func (api *ContractApi) EstimateCall(ctx context.Context, args CallArgs, stateOverrides *StateOverrides) (*TxReceipt, error) {
+	appState, err := api.getEstimateAppState(stateOverrides)
+	if err != nil {
+		return nil, err
+	}
 	vm := vm.NewVmImpl(appState, api.bc, api.bc.Head, nil, api.bc.Config())
-	tx, err := api.buildCallContractTx(args, true)
+	tx, err := api.buildCallContractTx(args, 0, true)
 	if err != nil {
 		return nil, err
 	}
 	var from *common.Address
 	if tx.Signed() {
 		if err := validation.ValidateTx(appState, tx, appState.State.FeePerGas(), validation.MempoolTx); err != nil {
-			return nil, err
+			return nil, txRejectedError(ctx, err)
 		}
 	} else {
 		from = &args.From
@@ -311,41 +309,53 @@ func (api *ContractApi) EstimateCall(args CallArgs) (*TxReceipt, error) {
 		appState.State.AddBalance(*tx.To, tx.Amount)
 	}
 
-	r := vm.Run(tx, from, -1)
+	r, output := runVm(api.bc, vm, appState, tx, from, -1, metrics.VmEstimate)
 	r.GasCost = api.bc.GetGasCost(appState, r.GasUsed)
-	return convertEstimatedReceipt(tx, r, appState.State.FeePerGas()), nil
+	receipt := convertEstimatedReceipt(tx, r, appState.State.FeePerGas(), stateContracts(appState))
+	receipt.DebugOutput = output
+	api.baseApi.decodeReceipt(receipt, appState)
+	return receipt, nil
 }
 
-func (api *ContractApi) EstimateTerminate(args TerminateArgs) (*TxReceipt, error) {
+func (api *ContractApi) EstimateTerminate(ctx context.Context, args TerminateArgs) (*TxReceipt, error) {
 	appState := api.baseApi.getAppStateForCheck()
 	vm := vm.NewVmImpl(appState, api.bc, api.bc.Head, nil, api.bc.Config())
-	tx, err := api.buildTerminateContractTx(args, true)
+	tx, err := api.buildTerminateContractTx(args, 0, true)
 	if err != nil {
 		return nil, err
 	}
 	var from *common.Address
 	if tx.Signed() {
 		if err := validation.ValidateTx(appState, tx, appState.State.FeePerGas(), validation.MempoolTx); err != nil {
-			return nil, err
+			return nil, txRejectedError(ctx, err)
 		}
 	} else {
 		from = &args.From
 	}
-	r := vm.Run(tx, from, -1)
+	r, output := runVm(api.bc, vm, appState, tx, from, -1, metrics.VmEstimate)
 	r.GasCost = api.bc.GetGasCost(appState, r.GasUsed)
-	return convertEstimatedReceipt(tx, r, appState.State.FeePerGas()), nil
+	receipt := convertEstimatedReceipt(tx, r, appState.State.FeePerGas(), stateContracts(appState))
+	receipt.DebugOutput = output
+	return receipt, nil
 }
 
-func convertReceipt(tx *types.Transaction, receipt *types.TxReceipt, feePerGas *big.Int) *TxReceipt {
+// convertReceipt converts the receipt, contracts classify the receipt error and may be nil
+func convertReceipt(tx *types.Transaction, receipt *types.TxReceipt, feePerGas *big.Int, contracts contractLookup) *TxReceipt {
 	fee := fee.CalculateFee(1, feePerGas, tx)
 	var err string
+	var errCode int
+	var errData *ErrorData
 	if receipt.Error != nil {
 		err = receipt.Error.Error()
+		kind := receiptErrorKind(tx, receipt, contracts)
+		errCode, errData = kind.code, &ErrorData{Kind: kind.kind, Cause: err}
 	}
 	txHash := receipt.TxHash
 	result := &TxReceipt{
 		Success:      receipt.Success,
 		Error:        err,
+		ErrorCode:    errCode,
+		ErrorData:    errData,
 		Method:       receipt.Method,
 		Contract:     receipt.ContractAddress,
 		TxHash:       &txHash,
@@ -355,24 +365,29 @@ func convertReceipt(tx *types.Transaction, receipt *types.TxReceipt, feePerGas *
 		ActionResult: convertActionResultBytes(receipt.ActionResult),
 	}
 	for _, e := range receipt.Events {
-		event := Event{
-			Event: e.EventName,
-		}
-		for i := range e.Data {
-			event.Args = append(event.Args, e.Data[i])
-		}
-		if !e.Contract.IsEmpty() {
-			event.Contract = e.Contract
-		} else {
-			event.Contract = receipt.ContractAddress
-		}
-		result.Events = append(result.Events, event)
+		result.Events = append(result.Events, convertEvent(e, receipt.ContractAddress))
 	}
 	return result
 }
 
-func convertEstimatedReceipt(tx *types.Transaction, receipt *types.TxReceipt, feePerGas *big.Int) *TxReceipt {
-	res := convertReceipt(tx, receipt, feePerGas)
+// convertEvent converts the receipt event, events without contract are emitted by the receipt contract
+func convertEvent(e *types.TxEvent, receiptContract common.Address) Event {
+	event := Event{
+		Event: e.EventName,
+	}
+	for i := range e.Data {
+		event.Args = append(event.Args, e.Data[i])
+	}
+	if !e.Contract.IsEmpty() {
+		event.Contract = e.Contract
+	} else {
+		event.Contract = receiptContract
+	}
+	return event
+}
+
+func convertEstimatedReceipt(tx *types.Transaction, receipt *types.TxReceipt, feePerGas *big.Int, contracts contractLookup) *TxReceipt {
+	res := convertReceipt(tx, receipt, feePerGas, contracts)
 	if !tx.Signed() {
 		res.TxHash = nil
 	}
@@ -380,7 +395,7 @@ func convertEstimatedReceipt(tx *types.Transaction, receipt *types.TxReceipt, fe
 }
 
 func (api *ContractApi) Deploy(ctx context.Context, args DeployArgs) (common.Hash, error) {
-	tx, err := api.buildDeployContractTx(args, false)
+	tx, err := api.buildDeployContractTx(args, 0, false)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -388,51 +403,81 @@ func (api *ContractApi) Deploy(ctx context.Context, args DeployArgs) (common.Has
 }
 
 func (api *ContractApi) Call(ctx context.Context, args CallArgs) (common.Hash, error) {
-	tx, err := api.buildCallContractTx(args, false)
+	tx, err := api.buildCallContractTx(args, 0, false)
 	if err != nil {
 		return common.Hash{}, err
 	}
-	fmt.Println(api.ReadData(args.Contract, "STATE", "string"))
 	return api.baseApi.sendInternalTx(ctx, tx)
 }
 func (api *ContractApi) Terminate(ctx context.Context, args TerminateArgs) (common.Hash, error) {
-	tx, err := api.buildTerminateContractTx(args, false)
+	tx, err := api.buildTerminateContractTx(args, 0, false)
 	if err != nil {
 		return common.Hash{}, err
 	}
 	return api.baseApi.sendInternalTx(ctx, tx)
 }
 
-func (api *ContractApi) ReadData(contract common.Address, key string, format string) (interface{}, error) {
-	data := api.baseApi.getReadonlyAppState().State.GetContractValue(contract, []byte(key))
+func (api *ContractApi) ReadData(contract common.Address, key string, format string, blockNumber *rpc.BlockNumber) (interface{}, error) {
+	appState, _, err := api.baseApi.getAppStateAt(blockNumberOrDefault(blockNumber, rpc.LatestBlockNumber))
+	if err != nil {
+		return nil, err
+	}
+	data := appState.State.GetContractValue(contract, []byte(key))
 	if data == nil {
 		return nil, errors.New("data is nil")
 	}
-	return conversion(format, data)
+	return codec.Decode(format, data)
 }
 
-func (api *ContractApi) ReadonlyCall(args ReadonlyCallArgs) (interface{}, error) {
-	vm := vm.NewVmImpl(api.baseApi.getReadonlyAppState(), api.bc, api.bc.Head, nil, api.bc.Config())
-	convertedArgs, err := args.Args.ToSlice()
+func (api *ContractApi) ReadonlyCall(ctx context.Context, args ReadonlyCallArgs, stateOverrides *StateOverrides) (interface{}, error) {
+	blockNumber := blockNumberOrDefault(args.BlockNumber, rpc.LatestBlockNumber)
+	var appState *appstate.AppState
+	var head *types.Header
+	var err error
+	if stateOverrides == nil {
+		appState, head, err = api.baseApi.getAppStateAt(blockNumber)
+	} else {
+		appState, head, err = api.baseApi.getAppStateForCheckAt(blockNumber)
+		if err == nil {
+			err = stateOverrides.Apply(appState)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	vm := vm.NewVmImpl(appState, api.bc, head, nil, api.bc.Config())
+	dynamicArgs, err := api.baseApi.resolveArgs(appState, args.Contract, args.Method, args.Args, args.NamedArgs)
 	if err != nil {
 		return nil, err
 	}
-	data, err := vm.Read(args.Contract, args.Method, convertedArgs...)
+	convertedArgs, err := dynamicArgs.ToSlice()
 	if err != nil {
 		return nil, err
 	}
-	return conversion(args.Format, data)
+	data, output, err := readVm(api.bc, vm, appState, args.Contract, args.Method, convertedArgs...)
+	if err != nil {
+		return nil, contractError(ctx, err, appState, args.Contract, args.Method)
+	}
+	format := args.Format
+	if format == "" {
+		if abi := api.baseApi.abi.Get(args.Contract, appState); abi != nil {
+			if m := abi.Method(args.Method); m != nil {
+				format = m.Returns
+			}
+		}
+	}
+	result, err := codec.Decode(format, data)
+	if err != nil || !args.Debug {
+		return result, err
+	}
+	return &ReadonlyCallResult{Result: result, DebugOutput: output}, nil
 }
 
-func (api *ContractApi) GetStake(contract common.Address) interface{} {
-	hash := api.baseApi.getReadonlyAppState().State.GetCodeHash(contract)
-	stake := api.baseApi.getReadonlyAppState().State.GetContractStake(contract)
-	return struct {
-		Hash  *common.Hash
-		Stake decimal.Decimal
-	}{
-		hash,
-		blockchain.ConvertToFloat(stake),
+func (api *ContractApi) GetStake(contract common.Address) *ContractStake {
+	appState := api.baseApi.getReadonlyAppState()
+	return &ContractStake{
+		Hash:  appState.State.GetCodeHash(contract),
+		Stake: blockchain.ConvertToFloat(appState.State.GetContractStake(contract)),
 	}
 }
 
@@ -454,80 +499,16 @@ func (api *ContractApi) Events(args EventsArgs) interface{} {
 	return list
 }
 
-func (api *ContractApi) ReadMap(contract common.Address, mapName string, key hexutil.Bytes, format string) (interface{}, error) {
-	data := api.baseApi.getReadonlyAppState().State.GetContractValue(contract, env.FormatMapKey([]byte(mapName), key))
-	if data == nil {
-		return nil, errors.New("data is nil")
-	}
-	return conversion(format, data)
-}
-
-func (api *ContractApi) IterateMap(contract common.Address, mapName string, continuationToken *hexutil.Bytes, keyFormat, valueFormat string, limit int) (*IterateMapResponse, error) {
-	state := api.baseApi.getReadonlyAppState().State
-
-	minKey := []byte(mapName)
-	maxKey := []byte(mapName)
-	for i := len([]byte(mapName)); i < common.MaxContractStoreKeyLength; i++ {
-		maxKey = append(maxKey, 0xFF)
-	}
-
-	if continuationToken != nil && len(*continuationToken) > 0 {
-		minKey = *continuationToken
-	}
-
-	var items []*MapItem
-	var err error
-	var token hexutil.Bytes
-	prefixLen := len([]byte(mapName))
-	state.IterateContractStore(contract, minKey, maxKey, func(key []byte, value []byte) bool {
-
-		if len(items) >= limit {
-			token = key
-			return true
-		}
-
-		item := new(MapItem)
-		item.Key, err = conversion(keyFormat, key[prefixLen:])
-		if err != nil {
-			return true
-		}
-		item.Value, err = conversion(valueFormat, value)
-		if err != nil {
-			return true
-		}
-		items = append(items, item)
-		return false
-	})
+func (api *ContractApi) ReadMap(contract common.Address, mapName string, key hexutil.Bytes, format string, blockNumber *rpc.BlockNumber) (interface{}, error) {
+	appState, _, err := api.baseApi.getAppStateAt(blockNumberOrDefault(blockNumber, rpc.LatestBlockNumber))
 	if err != nil {
 		return nil, err
 	}
-	return &IterateMapResponse{
-		Items:             items,
-		ContinuationToken: &token,
-	}, nil
-}
-
-func conversion(convertTo string, data []byte) (interface{}, error) {
-	switch convertTo {
-	case "byte":
-		return helpers.ExtractByte(0, data)
-	case "uint64":
-		return helpers.ExtractUInt64(0, data)
-	case "string":
-		return string(data), nil
-	case "bigint":
-		v := new(big.Int)
-		v.SetBytes(data)
-		return v.String(), nil
-	case "hex":
-		return hexutil.Encode(data), nil
-	case "dna":
-		v := new(big.Int)
-		v.SetBytes(data)
-		return blockchain.ConvertToFloat(v), nil
-	default:
-		return hexutil.Encode(data), nil
+	data := appState.State.GetContractValue(contract, env.FormatMapKey([]byte(mapName), key))
+	if data == nil {
+		return nil, errors.New("data is nil")
 	}
+	return codec.Decode(format, data)
 }
 
 func convertActionResultBytes(actionResult []byte) *ActionResult {
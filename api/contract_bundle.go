@@ -0,0 +1,163 @@
+package api
+
+import (
+	"github.com/idena-network/idena-contract-runner/metrics"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/blockchain/validation"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	BundleDeploy    = "deploy"
+	BundleCall      = "call"
+	BundleTerminate = "terminate"
+	BundleTransfer  = "transfer"
+)
+
+type TransferArgs struct {
+	From   common.Address  `json:"from"`
+	To     common.Address  `json:"to"`
+	Amount decimal.Decimal `json:"amount"`
+	MaxFee decimal.Decimal `json:"maxFee"`
+}
+
+type BundleOperation struct {
+	Type      string         `json:"type"`
+	Deploy    *DeployArgs    `json:"deploy"`
+	Call      *CallArgs      `json:"call"`
+	Terminate *TerminateArgs `json:"terminate"`
+	Transfer  *TransferArgs  `json:"transfer"`
+}
+
+type SimulateBundleArgs struct {
+	Operations     []*BundleOperation `json:"operations"`
+	StopOnFailure  bool               `json:"stopOnFailure"`
+	StateOverrides *StateOverrides    `json:"stateOverrides"`
+}
+
+type SimulateBundleResponse struct {
+	Success  bool         `json:"success"`
+	Receipts []*TxReceipt `json:"receipts"`
+}
+
+// SimulateBundle runs the operations one by one on a single check state, so every operation sees the effects of the
+// previous ones: balances, nonces, deployed contracts and their storage. Operations run with the gas limit the chain
+// gives transactions with their max fee. Nothing is committed.
+func (api *ContractApi) SimulateBundle(args SimulateBundleArgs) (*SimulateBundleResponse, error) {
+	appState, err := api.getEstimateAppState(args.StateOverrides)
+	if err != nil {
+		return nil, err
+	}
+	result := &SimulateBundleResponse{Success: true}
+	for i, op := range args.Operations {
+		if op == nil {
+			return nil, errors.Errorf("operation %v is empty", i)
+		}
+		tx, from, err := api.buildBundleTx(appState, op)
+		if err != nil {
+			return nil, errors.Wrapf(err, "operation %v", i)
+		}
+		receipt := api.applyBundleTx(appState, tx, from)
+		api.baseApi.decodeReceipt(receipt, appState)
+		result.Receipts = append(result.Receipts, receipt)
+		if !receipt.Success {
+			result.Success = false
+			if args.StopOnFailure {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (api *ContractApi) buildBundleTx(appState *appstate.AppState, op *BundleOperation) (*types.Transaction, common.Address, error) {
+	var from common.Address
+	var build func(nonce uint32) (*types.Transaction, error)
+	switch op.Type {
+	case BundleDeploy:
+		if op.Deploy == nil {
+			return nil, from, errors.New("deploy args are empty")
+		}
+		from = op.Deploy.From
+		build = func(nonce uint32) (*types.Transaction, error) {
+			return api.buildDeployContractTx(*op.Deploy, nonce, true)
+		}
+	case BundleCall:
+		if op.Call == nil {
+			return nil, from, errors.New("call args are empty")
+		}
+		from = op.Call.From
+		build = func(nonce uint32) (*types.Transaction, error) {
+			return api.buildCallContractTx(*op.Call, nonce, true)
+		}
+	case BundleTerminate:
+		if op.Terminate == nil {
+			return nil, from, errors.New("terminate args are empty")
+		}
+		from = op.Terminate.From
+		build = func(nonce uint32) (*types.Transaction, error) {
+			return api.buildTerminateContractTx(*op.Terminate, nonce, true)
+		}
+	case BundleTransfer:
+		if op.Transfer == nil {
+			return nil, from, errors.New("transfer args are empty")
+		}
+		from = op.Transfer.From
+		build = func(nonce uint32) (*types.Transaction, error) {
+			sender := from
+			if sender == (common.Address{}) {
+				sender = api.baseApi.getCurrentCoinbase()
+			}
+			tx := api.baseApi.getTx(sender, &op.Transfer.To, types.SendTx, op.Transfer.Amount, op.Transfer.MaxFee, decimal.Zero, nonce, 0, nil)
+			return api.signIfNeeded(sender, tx, true)
+		}
+	default:
+		return nil, from, errors.Errorf("unknown operation type: \"%v\"", op.Type)
+	}
+	if from == (common.Address{}) {
+		from = api.baseApi.getCurrentCoinbase()
+	}
+	tx, err := build(nextNonce(appState, from))
+	return tx, from, err
+}
+
+func nextNonce(appState *appstate.AppState, addr common.Address) uint32 {
+	if appState.State.GetEpoch(addr) < appState.State.Epoch() {
+		return 1
+	}
+	return appState.State.GetNonce(addr) + 1
+}
+
+func (api *ContractApi) applyBundleTx(appState *appstate.AppState, tx *types.Transaction, from common.Address) *TxReceipt {
+	feePerGas := appState.State.FeePerGas()
+	if tx.Signed() {
+		if err := validation.ValidateTx(appState, tx, feePerGas, validation.MempoolTx); err != nil {
+			return failedBundleReceipt(tx, err)
+		}
+	}
+	receipt, output, err := applyTxOnState(api.bc, appState, api.bc.Head, tx, from, !tx.Signed(), metrics.VmBundle)
+	if err != nil {
+		return failedBundleReceipt(tx, err)
+	}
+	result := convertEstimatedReceipt(tx, receipt, feePerGas, stateContracts(appState))
+	result.DebugOutput = output
+	return result
+}
+
+func failedBundleReceipt(tx *types.Transaction, err error) *TxReceipt {
+	result := &TxReceipt{
+		Success: false,
+		Error:   err.Error(),
+	}
+	if tx.Signed() {
+		hash := tx.Hash()
+		result.TxHash = &hash
+	}
+	if tx.To != nil {
+		result.Contract = *tx.To
+	}
+	return result
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/idena-network/idena-contract-runner/codec"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+const (
+	CollectionVector = "vector"
+	CollectionDeque  = "deque"
+	CollectionMap    = "map"
+
+	defaultCollectionLimit = 100
+)
+
+// CollectionLayout describes how a persistent collection of the sdk is stored. Every key of the collection starts
+// with its prefix followed by the separator: a vector keeps its length under LengthKey and items under encoded
+// indexes, a deque additionally keeps the index of its first item under FrontKey, a map keeps values under encoded
+// keys. Nested collections use the key in the parent map as a part of their prefix.
+//
+// The defaults are the layout of idena-sdk-as collections: "len" and "front" keys without a separator, uint32 lengths
+// and indexes, int32 deque indexes. They are not pinned to an sdk release since the runner doesn't depend on the sdk,
+// contracts built with a release that stores collections differently pass their layout.
+type CollectionLayout struct {
+	Separator    string `json:"separator"`
+	LengthKey    string `json:"lengthKey"`
+	FrontKey     string `json:"frontKey"`
+	LengthFormat string `json:"lengthFormat"`
+	IndexFormat  string `json:"indexFormat"`
+}
+
+func defaultCollectionLayout(kind string) *CollectionLayout {
+	layout := &CollectionLayout{
+		LengthKey:    "len",
+		FrontKey:     "front",
+		LengthFormat: "uint32",
+		IndexFormat:  "uint32",
+	}
+	if kind == CollectionDeque {
+		layout.IndexFormat = "int32"
+	}
+	return layout
+}
+
+func (l *CollectionLayout) withDefaults(kind string) *CollectionLayout {
+	result := defaultCollectionLayout(kind)
+	if l == nil {
+		return result
+	}
+	result.Separator = l.Separator
+	if l.LengthKey != "" {
+		result.LengthKey = l.LengthKey
+	}
+	if l.FrontKey != "" {
+		result.FrontKey = l.FrontKey
+	}
+	if l.LengthFormat != "" {
+		result.LengthFormat = l.LengthFormat
+	}
+	if l.IndexFormat != "" {
+		result.IndexFormat = l.IndexFormat
+	}
+	return result
+}
+
+// CollectionKey is a key of a parent map on the path to a nested collection
+type CollectionKey struct {
+	Format string `json:"format"`
+	Value  string `json:"value"`
+}
+
+type ReadCollectionArgs struct {
+	Contract common.Address   `json:"contract"`
+	Kind     string           `json:"kind"`
+	Prefix   string           `json:"prefix"`
+	Path     []*CollectionKey `json:"path"`
+	// format of items of vectors and deques or values of maps
+	Format      string            `json:"format"`
+	KeyFormat   string            `json:"keyFormat"`
+	Offset      uint64            `json:"offset"`
+	Limit       uint64            `json:"limit"`
+	Layout      *CollectionLayout `json:"layout"`
+	BlockNumber *rpc.BlockNumber  `json:"blockNumber"`
+}
+
+type ReadCollectionResponse struct {
+	Kind string `json:"kind"`
+	// number of items of a vector or a deque, number of entries of a map
+	Length  uint64        `json:"length"`
+	Items   []interface{} `json:"items,omitempty"`
+	Entries []*MapItem    `json:"entries,omitempty"`
+	// number of keys under the prefix of a map that are not its entries: keys of nested collections and keys that
+	// can't be decoded in the key format
+	Skipped uint64 `json:"skipped,omitempty"`
+}
+
+// ReadCollection reads a slice of a persistent vector, deque or map of the sdk starting from the offset
+func (api *ContractApi) ReadCollection(args ReadCollectionArgs) (*ReadCollectionResponse, error) {
+	appState, _, err := api.baseApi.getAppStateAt(blockNumberOrDefault(args.BlockNumber, rpc.LatestBlockNumber))
+	if err != nil {
+		return nil, err
+	}
+	layout := args.Layout.withDefaults(args.Kind)
+	prefix := []byte(args.Prefix)
+	for i, key := range args.Path {
+		data, err := codec.Encode(key.Format, key.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "path key %v", i)
+		}
+		prefix = append(append(prefix, layout.Separator...), data...)
+	}
+	prefix = append(prefix, layout.Separator...)
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultCollectionLimit
+	}
+	r := &collectionReader{
+		appState: appState,
+		contract: args.Contract,
+		prefix:   prefix,
+		layout:   layout,
+	}
+	switch args.Kind {
+	case CollectionVector:
+		return r.readList(args.Kind, 0, args.Format, args.Offset, limit)
+	case CollectionDeque:
+		front, err := r.readInt(layout.FrontKey, layout.IndexFormat)
+		if err != nil {
+			return nil, errors.Wrap(err, "front index")
+		}
+		return r.readList(args.Kind, front, args.Format, args.Offset, limit)
+	case CollectionMap:
+		return r.readMap(args.KeyFormat, args.Format, args.Offset, limit)
+	default:
+		return nil, errors.Errorf("unknown collection kind: \"%v\"", args.Kind)
+	}
+}
+
+type collectionReader struct {
+	appState *appstate.AppState
+	contract common.Address
+	prefix   []byte
+	layout   *CollectionLayout
+}
+
+func (r *collectionReader) key(suffix []byte) []byte {
+	return append(append([]byte{}, r.prefix...), suffix...)
+}
+
+// readInt reads an integer stored under the service key of the collection, a missing value means zero
+func (r *collectionReader) readInt(name string, format string) (int64, error) {
+	data := r.appState.State.GetContractValue(r.contract, r.key([]byte(name)))
+	if data == nil {
+		return 0, nil
+	}
+	value, err := codec.Decode(format, data)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(fmt.Sprint(value), 10, 64)
+}
+
+func (r *collectionReader) readList(kind string, front int64, format string, offset, limit uint64) (*ReadCollectionResponse, error) {
+	length, err := r.readInt(r.layout.LengthKey, r.layout.LengthFormat)
+	if err != nil {
+		return nil, errors.Wrap(err, "length")
+	}
+	result := &ReadCollectionResponse{Kind: kind, Length: uint64(length)}
+	for i := offset; i < result.Length && i < offset+limit; i++ {
+		index, err := codec.Encode(r.layout.IndexFormat, strconv.FormatInt(front+int64(i), 10))
+		if err != nil {
+			return nil, errors.Wrapf(err, "index %v", i)
+		}
+		var item interface{}
+		if data := r.appState.State.GetContractValue(r.contract, r.key(index)); data != nil {
+			if item, err = codec.Decode(format, data); err != nil {
+				return nil, errors.Wrapf(err, "item %v", i)
+			}
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+// readMap reads entries of the map, keys of nested vectors and deques are recognized by their length and front keys
+// and skipped with their items. Keys of nested maps are skipped when they can't be decoded in the key format.
+func (r *collectionReader) readMap(keyFormat, valueFormat string, offset, limit uint64) (*ReadCollectionResponse, error) {
+	var keys, values [][]byte
+	r.appState.State.IterateContractStore(r.contract, r.prefix, maxKeyWithPrefix(r.prefix), func(key []byte, value []byte) bool {
+		if !bytes.HasPrefix(key, r.prefix) {
+			return false
+		}
+		keys = append(keys, append([]byte{}, key[len(r.prefix):]...))
+		values = append(values, value)
+		return false
+	})
+	nested := r.nestedPrefixes(keys, keyFormat)
+	result := &ReadCollectionResponse{Kind: CollectionMap}
+	for i, key := range keys {
+		if hasAnyPrefix(key, nested) {
+			result.Skipped++
+			continue
+		}
+		decodedKey, err := codec.Decode(keyFormat, key)
+		if err != nil {
+			result.Skipped++
+			continue
+		}
+		index := result.Length
+		result.Length++
+		if index < offset || index >= offset+limit {
+			continue
+		}
+		item := &MapItem{Key: decodedKey}
+		if item.Value, err = codec.Decode(valueFormat, values[i]); err != nil {
+			return nil, errors.Wrapf(err, "value of key %v", decodedKey)
+		}
+		result.Entries = append(result.Entries, item)
+	}
+	return result, nil
+}
+
+// nestedPrefixes returns prefixes of nested collections, i.e. the keys of the map followed by the separator and the
+// length or the front key
+func (r *collectionReader) nestedPrefixes(keys [][]byte, keyFormat string) [][]byte {
+	var result [][]byte
+	for _, key := range keys {
+		for _, name := range []string{r.layout.LengthKey, r.layout.FrontKey} {
+			suffix := []byte(r.layout.Separator + name)
+			if len(key) <= len(suffix) || !bytes.HasSuffix(key, suffix) {
+				continue
+			}
+			if _, err := codec.Decode(keyFormat, key[:len(key)-len(suffix)]); err == nil {
+				result = append(result, key[:len(key)-len(name)])
+			}
+		}
+	}
+	return result
+}
+
+func hasAnyPrefix(key []byte, prefixes [][]byte) bool {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/idena-network/idena-contract-runner/codec"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+const (
+	defaultEventsLimit = 100
+	eventsTokenLength  = 12
+)
+
+type GetEventsArgs struct {
+	Contract  *common.Address   `json:"contract"`
+	Event     string            `json:"event"`
+	FromBlock *rpc.BlockNumber  `json:"fromBlock"`
+	ToBlock   *rpc.BlockNumber  `json:"toBlock"`
+	TxHash    *common.Hash      `json:"txHash"`
+	Args      []*EventArgFilter `json:"args"`
+	// formats of event args used for decoding when the abi of the contract is not registered
+	Formats           []string       `json:"formats"`
+	Limit             int            `json:"limit"`
+	ContinuationToken *hexutil.Bytes `json:"continuationToken"`
+}
+
+// EventArgFilter matches events whose arg, referenced by index or by abi name, is equal to the encoded value
+type EventArgFilter struct {
+	Index  *int   `json:"index"`
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Value  string `json:"value"`
+}
+
+type ContractEvent struct {
+	Event
+	BlockHeight uint64      `json:"blockHeight"`
+	BlockHash   common.Hash `json:"blockHash"`
+	TxHash      common.Hash `json:"txHash"`
+	TxIndex     uint32      `json:"txIndex"`
+	// position of the event among all events of the block
+	LogIndex uint32 `json:"logIndex"`
+}
+
+type GetEventsResponse struct {
+	Events            []*ContractEvent `json:"events"`
+	ContinuationToken *hexutil.Bytes   `json:"continuationToken"`
+}
+
+type eventPosition struct {
+	height   uint64
+	logIndex uint32
+}
+
+func (p eventPosition) token() hexutil.Bytes {
+	data := make([]byte, eventsTokenLength)
+	binary.BigEndian.PutUint64(data, p.height)
+	binary.BigEndian.PutUint32(data[8:], p.logIndex)
+	return data
+}
+
+func parseEventsToken(token hexutil.Bytes) (eventPosition, error) {
+	if len(token) != eventsTokenLength {
+		return eventPosition{}, errors.New("invalid continuation token")
+	}
+	return eventPosition{
+		height:   binary.BigEndian.Uint64(token),
+		logIndex: binary.BigEndian.Uint32(token[8:]),
+	}, nil
+}
+
+// GetEvents returns events of mined transactions ordered by block height and log index
+func (api *ContractApi) GetEvents(args GetEventsArgs) (*GetEventsResponse, error) {
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultEventsLimit
+	}
+	from := api.baseApi.blockHeight(blockNumberOrDefault(args.FromBlock, 1))
+	to := api.baseApi.blockHeight(blockNumberOrDefault(args.ToBlock, rpc.LatestBlockNumber))
+	if head := api.bc.Head.Height(); to > head {
+		to = head
+	}
+	if args.TxHash != nil {
+		idx := api.bc.GetTxIndex(*args.TxHash)
+		if idx == nil {
+			return &GetEventsResponse{}, nil
+		}
+		block := api.bc.GetBlock(idx.BlockHash)
+		if block == nil || block.Height() < from || block.Height() > to {
+			return &GetEventsResponse{}, nil
+		}
+		from, to = block.Height(), block.Height()
+	}
+	start := eventPosition{height: from}
+	if args.ContinuationToken != nil && len(*args.ContinuationToken) > 0 {
+		var err error
+		if start, err = parseEventsToken(*args.ContinuationToken); err != nil {
+			return nil, err
+		}
+	}
+
+	appState := api.baseApi.getReadonlyAppState()
+	matcher, err := api.newEventMatcher(args, appState)
+	if err != nil {
+		return nil, err
+	}
+	result := &GetEventsResponse{}
+	for height := start.height; height <= to; height++ {
+		block := api.bc.GetBlockByHeight(height)
+		if block == nil {
+			continue
+		}
+		var logIndex uint32
+		for txIndex, tx := range block.Body.Transactions {
+			if args.TxHash != nil && tx.Hash() != *args.TxHash {
+				logIndex += api.countEvents(tx)
+				continue
+			}
+			receipt := api.bc.GetReceipt(tx.Hash())
+			if receipt == nil {
+				continue
+			}
+			for _, e := range receipt.Events {
+				position := eventPosition{height, logIndex}
+				logIndex++
+				if height == start.height && position.logIndex < start.logIndex {
+					continue
+				}
+				event := convertEvent(e, receipt.ContractAddress)
+				if !matcher.match(&event) {
+					continue
+				}
+				if len(result.Events) >= limit {
+					token := position.token()
+					result.ContinuationToken = &token
+					return result, nil
+				}
+				api.decodeEventValues(&event, args.Formats, appState)
+				result.Events = append(result.Events, &ContractEvent{
+					Event:       event,
+					BlockHeight: height,
+					BlockHash:   block.Hash(),
+					TxHash:      tx.Hash(),
+					TxIndex:     uint32(txIndex),
+					LogIndex:    position.logIndex,
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+func (api *ContractApi) countEvents(tx *types.Transaction) uint32 {
+	if receipt := api.bc.GetReceipt(tx.Hash()); receipt != nil {
+		return uint32(len(receipt.Events))
+	}
+	return 0
+}
+
+func (api *ContractApi) decodeEventValues(event *Event, formats []string, appState *appstate.AppState) {
+	api.baseApi.decodeEvent(event, appState)
+	if event.Values != nil || len(formats) == 0 {
+		return
+	}
+	e := &AbiEvent{Name: event.Event}
+	for i, format := range formats {
+		e.Args = append(e.Args, &AbiParam{Name: "arg" + strconv.Itoa(i), Type: format})
+	}
+	event.Values, _ = e.Decode(event.rawArgs())
+}
+
+type eventArgMatch struct {
+	index int
+	value []byte
+}
+
+type eventMatcher struct {
+	contract *common.Address
+	event    string
+	args     []eventArgMatch
+}
+
+func (api *ContractApi) newEventMatcher(args GetEventsArgs, appState *appstate.AppState) (*eventMatcher, error) {
+	m := &eventMatcher{contract: args.Contract, event: args.Event}
+	for i, f := range args.Args {
+		if f == nil {
+			continue
+		}
+		index, format := -1, f.Format
+		if f.Index != nil {
+			index = *f.Index
+		}
+		if f.Name != "" {
+			param, paramIndex, err := api.abiEventParam(args, f.Name, appState)
+			if err != nil {
+				return nil, errors.Wrapf(err, "arg filter %v", i)
+			}
+			index = paramIndex
+			if format == "" {
+				format = param.Type
+			}
+		}
+		if index < 0 {
+			return nil, errors.Errorf("arg filter %v: either index or name should be specified", i)
+		}
+		value, err := codec.Encode(format, f.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "arg filter %v", i)
+		}
+		m.args = append(m.args, eventArgMatch{index: index, value: value})
+	}
+	return m, nil
+}
+
+func (api *ContractApi) abiEventParam(args GetEventsArgs, name string, appState *appstate.AppState) (*AbiParam, int, error) {
+	if args.Contract == nil || args.Event == "" {
+		return nil, 0, errors.New("filtering by arg name requires contract and event")
+	}
+	abi := api.baseApi.abi.Get(*args.Contract, appState)
+	if abi == nil {
+		return nil, 0, errors.Errorf("abi of contract %v is not registered", args.Contract.Hex())
+	}
+	e := abi.Event(args.Event)
+	if e == nil {
+		return nil, 0, errors.Errorf("event \"%v\" is not declared in abi of contract %v", args.Event, args.Contract.Hex())
+	}
+	for i, param := range e.Args {
+		if param.Name == name {
+			return param, i, nil
+		}
+	}
+	return nil, 0, errors.Errorf("unknown arg \"%v\" of event \"%v\"", name, args.Event)
+}
+
+func (m *eventMatcher) match(event *Event) bool {
+	if m.contract != nil && event.Contract != *m.contract {
+		return false
+	}
+	if m.event != "" && event.Event != m.event {
+		return false
+	}
+	for _, arg := range m.args {
+		if arg.index >= len(event.Args) || !bytes.Equal(event.Args[arg.index], arg.value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"github.com/idena-network/idena-contract-runner/wasm"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+type ContractStake struct {
+	Hash  *common.Hash    `json:"hash"`
+	Stake decimal.Decimal `json:"stake"`
+}
+
+type ContractInfo struct {
+	Address      common.Address  `json:"address"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Deployer     *common.Address `json:"deployer"`
+	DeployHeight uint64          `json:"deployHeight"`
+	DeployTx     *common.Hash    `json:"deployTx"`
+	Stake        decimal.Decimal `json:"stake"`
+	// number of storage entries and total size of their keys and values in bytes
+	StorageKeys int `json:"storageKeys"`
+	StorageSize int `json:"storageSize"`
+	// wasm contracts have code, predefined contracts are identified by the code hash only
+	CodeSize int `json:"codeSize"`
+}
+
+type WasmImport struct {
+	Module string `json:"module"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+}
+
+type WasmExport struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Index uint32 `json:"index"`
+	// size of the function body in bytes, only for exported functions
+	BodySize uint32 `json:"bodySize,omitempty"`
+}
+
+type CodeInspection struct {
+	CodeHash  common.Hash   `json:"codeHash"`
+	CodeSize  int           `json:"codeSize"`
+	Exports   []*WasmExport `json:"exports"`
+	Imports   []*WasmImport `json:"imports"`
+	Functions int           `json:"functions"`
+}
+
+type contractDeployment struct {
+	deployer common.Address
+	height   uint64
+	tx       common.Hash
+}
+
+// List returns all contracts of the current state ordered by deploy height
+func (api *ContractApi) List() []*ContractInfo {
+	appState := api.baseApi.getReadonlyAppState()
+	deployments := api.contractDeployments()
+	var result []*ContractInfo
+	appState.State.IterateOverAccounts(func(addr common.Address, account state.Account) {
+		if account.Contract == nil {
+			return
+		}
+		info := &ContractInfo{
+			Address:  addr,
+			CodeHash: account.Contract.CodeHash,
+			Stake:    blockchain.ConvertToFloat(account.Contract.Stake),
+			CodeSize: len(appState.State.GetContractCode(addr)),
+		}
+		if d, ok := deployments[addr]; ok {
+			deployer, tx := d.deployer, d.tx
+			info.Deployer, info.DeployHeight, info.DeployTx = &deployer, d.height, &tx
+		}
+		appState.State.IterateContractStore(addr, nil, nil, func(key []byte, value []byte) bool {
+			info.StorageKeys++
+			info.StorageSize += len(key) + len(value)
+			return false
+		})
+		result = append(result, info)
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].DeployHeight != result[j].DeployHeight {
+			return result[i].DeployHeight < result[j].DeployHeight
+		}
+		return result[i].Address.Hex() < result[j].Address.Hex()
+	})
+	return result
+}
+
+// contractDeployments scans mined deploy transactions, the chain is short living so there is no index for them
+func (api *ContractApi) contractDeployments() map[common.Address]*contractDeployment {
+	result := make(map[common.Address]*contractDeployment)
+	for height := uint64(1); height <= api.bc.Head.Height(); height++ {
+		block := api.bc.GetBlockByHeight(height)
+		if block == nil {
+			continue
+		}
+		for _, tx := range block.Body.Transactions {
+			if tx.Type != types.DeployContractTx {
+				continue
+			}
+			receipt := api.bc.GetReceipt(tx.Hash())
+			if receipt == nil || !receipt.Success {
+				continue
+			}
+			sender, _ := types.Sender(tx)
+			result[receipt.ContractAddress] = &contractDeployment{deployer: sender, height: height, tx: tx.Hash()}
+		}
+	}
+	return result
+}
+
+func (api *ContractApi) GetCode(contract common.Address) (hexutil.Bytes, error) {
+	code := api.baseApi.getReadonlyAppState().State.GetContractCode(contract)
+	if len(code) == 0 {
+		return nil, errors.Errorf("%v is not a wasm contract", contract.Hex())
+	}
+	return code, nil
+}
+
+// InspectCode parses the wasm code of the contract and returns its interface
+func (api *ContractApi) InspectCode(contract common.Address) (*CodeInspection, error) {
+	appState := api.baseApi.getReadonlyAppState()
+	code := appState.State.GetContractCode(contract)
+	if len(code) == 0 {
+		return nil, errors.Errorf("%v is not a wasm contract", contract.Hex())
+	}
+	module, err := wasm.ParseModule(code)
+	if err != nil {
+		return nil, err
+	}
+	result := &CodeInspection{
+		CodeHash:  *appState.State.GetCodeHash(contract),
+		CodeSize:  len(code),
+		Functions: len(module.Functions),
+	}
+	for _, imp := range module.Imports {
+		result.Imports = append(result.Imports, &WasmImport{Module: imp.Module, Name: imp.Name, Kind: imp.Kind.String()})
+	}
+	for _, exp := range module.Exports {
+		export := &WasmExport{Name: exp.Name, Kind: exp.Kind.String(), Index: exp.Index}
+		if exp.Kind == wasm.KindFunction && int(exp.Index) < len(module.Functions) {
+			export.BodySize = module.Functions[exp.Index].BodySize
+		}
+		result.Exports = append(result.Exports, export)
+	}
+	return result, nil
+}
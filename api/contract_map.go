@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-contract-runner/codec"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultMapLimit = 100
+
+	mapTokenVersion = 1
+	mapTokenForward = 0
+	mapTokenReverse = 1
+)
+
+// IterateMapOptions narrows down the iteration, prefix and bounds are encoded with the key format of the request
+type IterateMapOptions struct {
+	Prefix *string `json:"prefix"`
+	// inclusive lower bound
+	From *string `json:"from"`
+	// exclusive upper bound
+	To      *string `json:"to"`
+	Reverse bool    `json:"reverse"`
+	// Count returns the number of matching items instead of the items
+	Count bool `json:"count"`
+}
+
+// mapRange is an inclusive range of full storage keys with an optional exclusive upper bound
+type mapRange struct {
+	prefixLen int
+	min       []byte
+	max       []byte
+	to        []byte
+}
+
+func newMapRange(mapName []byte, keyFormat string, options *IterateMapOptions) (*mapRange, error) {
+	encodeKey := func(name string, value *string) ([]byte, error) {
+		if value == nil {
+			return nil, nil
+		}
+		data, err := codec.Encode(keyFormat, *value)
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		return append(append([]byte{}, mapName...), data...), nil
+	}
+	prefix, err := encodeKey("prefix", options.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	if prefix == nil {
+		prefix = mapName
+	}
+	r := &mapRange{
+		prefixLen: len(mapName),
+		min:       prefix,
+		max:       maxKeyWithPrefix(prefix),
+	}
+	from, err := encodeKey("from", options.From)
+	if err != nil {
+		return nil, err
+	}
+	if from != nil && bytes.Compare(from, r.min) > 0 {
+		r.min = from
+	}
+	if r.to, err = encodeKey("to", options.To); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func maxKeyWithPrefix(prefix []byte) []byte {
+	result := append([]byte{}, prefix...)
+	for i := len(prefix); i < common.MaxContractStoreKeyLength; i++ {
+		result = append(result, 0xFF)
+	}
+	return result
+}
+
+// iterate calls f for the keys of the range in ascending order until f returns true
+func (r *mapRange) iterate(stateDb *state.StateDB, contract common.Address, f func(key []byte, value []byte) bool) {
+	if bytes.Compare(r.min, r.max) > 0 {
+		return
+	}
+	stateDb.IterateContractStore(contract, r.min, r.max, func(key []byte, value []byte) bool {
+		if r.to != nil && bytes.Compare(key, r.to) >= 0 {
+			return true
+		}
+		return f(key, value)
+	})
+}
+
+// iterateDesc calls f for the keys of the range of the committed state at the height in descending order until f
+// returns true
+func (r *mapRange) iterateDesc(bc *chain.MemBlockchain, height uint64, contract common.Address, f func(key []byte, value []byte) bool) error {
+	if bytes.Compare(r.min, r.max) > 0 {
+		return nil
+	}
+	// the key right after max is the exclusive bound of the inclusive range
+	end := append(append([]byte{}, r.max...), 0)
+	if r.to != nil && bytes.Compare(r.to, end) < 0 {
+		end = r.to
+	}
+	return bc.IterateContractStoreDesc(height, contract, r.min, end, f)
+}
+
+// mapToken is a versioned, order bound and map relative reference to the first key of the next page,
+// it stays valid when items are added or removed before it
+func mapToken(relativeKey []byte, reverse bool) hexutil.Bytes {
+	direction := byte(mapTokenForward)
+	if reverse {
+		direction = mapTokenReverse
+	}
+	return append([]byte{mapTokenVersion, direction}, relativeKey...)
+}
+
+func parseMapToken(token hexutil.Bytes, reverse bool) ([]byte, error) {
+	if len(token) < 2 || token[0] != mapTokenVersion {
+		return nil, errors.New("invalid continuation token")
+	}
+	if (token[1] == mapTokenReverse) != reverse {
+		return nil, errors.New("continuation token doesn't match the iteration order")
+	}
+	return token[2:], nil
+}
+
+type mapEntry struct {
+	key   []byte
+	value []byte
+}
+
+func (api *ContractApi) IterateMap(contract common.Address, mapName string, continuationToken *hexutil.Bytes, keyFormat, valueFormat string, limit int, blockNumber *rpc.BlockNumber, options *IterateMapOptions) (*IterateMapResponse, error) {
+	appState, head, err := api.baseApi.getAppStateAt(blockNumberOrDefault(blockNumber, rpc.LatestBlockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &IterateMapOptions{}
+	}
+	if limit <= 0 {
+		limit = defaultMapLimit
+	}
+	r, err := newMapRange([]byte(mapName), keyFormat, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Count {
+		count := 0
+		r.iterate(appState.State, contract, func(key []byte, value []byte) bool {
+			count++
+			return false
+		})
+		return &IterateMapResponse{Count: &count}, nil
+	}
+
+	if continuationToken != nil && len(*continuationToken) > 0 {
+		relativeKey, err := parseMapToken(*continuationToken, options.Reverse)
+		if err != nil {
+			return nil, err
+		}
+		key := append([]byte(mapName), relativeKey...)
+		if options.Reverse {
+			if bytes.Compare(key, r.max) < 0 {
+				r.max = key
+			}
+		} else if bytes.Compare(key, r.min) > 0 {
+			r.min = key
+		}
+	}
+
+	var entries []*mapEntry
+	collect := func(key []byte, value []byte) bool {
+		entries = append(entries, &mapEntry{common.CopyBytes(key), common.CopyBytes(value)})
+		return len(entries) > limit
+	}
+	if options.Reverse {
+		// the pending state has no changes over the head state, so both are read from the committed state of the head
+		if err := r.iterateDesc(api.baseApi.chain, head.Height(), contract, collect); err != nil {
+			return nil, err
+		}
+	} else {
+		r.iterate(appState.State, contract, collect)
+	}
+
+	result := &IterateMapResponse{}
+	if len(entries) > limit {
+		token := mapToken(entries[limit].key[r.prefixLen:], options.Reverse)
+		result.ContinuationToken = &token
+		entries = entries[:limit]
+	}
+	for _, entry := range entries {
+		item := new(MapItem)
+		if item.Key, err = codec.Decode(keyFormat, entry.key[r.prefixLen:]); err != nil {
+			return nil, errors.Wrapf(err, "key %v", hexutil.Encode(entry.key[r.prefixLen:]))
+		}
+		if item.Value, err = codec.Decode(valueFormat, entry.value); err != nil {
+			return nil, errors.Wrapf(err, "value of key %v", hexutil.Encode(entry.key[r.prefixLen:]))
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
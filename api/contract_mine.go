@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+)
+
+// maxMineBlocks bounds the mining of a single tx, a tx which waits for a missing nonce is never mined
+const maxMineBlocks = 10
+
+type MinedTxReceipt struct {
+	*TxReceipt
+	BlockHeight uint64      `json:"blockHeight"`
+	BlockHash   common.Hash `json:"blockHash"`
+	TxIndex     uint32      `json:"txIndex"`
+}
+
+func (api *ContractApi) DeployAndMine(ctx context.Context, args DeployArgs) (*MinedTxReceipt, error) {
+	hash, err := api.Deploy(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return api.mineTx(hash)
+}
+
+func (api *ContractApi) CallAndMine(ctx context.Context, args CallArgs) (*MinedTxReceipt, error) {
+	hash, err := api.Call(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return api.mineTx(hash)
+}
+
+func (api *ContractApi) TerminateAndMine(ctx context.Context, args TerminateArgs) (*MinedTxReceipt, error) {
+	hash, err := api.Terminate(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return api.mineTx(hash)
+}
+
+// mineTx generates blocks one by one until the tx is included
+func (api *ContractApi) mineTx(hash common.Hash) (*MinedTxReceipt, error) {
+	for i := 0; i < maxMineBlocks; i++ {
+		api.bc.GenerateBlocks(1)
+		if receipt := api.minedReceipt(hash); receipt != nil {
+			return receipt, nil
+		}
+		if api.baseApi.txpool.GetTx(hash) == nil {
+			if removed := api.bc.TxJournal().Find(hash); removed != nil {
+				return nil, errors.Errorf("tx %v was %v: %v", hash.Hex(), removed.Kind, removed.Reason)
+			}
+			return nil, errors.Errorf("tx %v left the mempool without being mined", hash.Hex())
+		}
+	}
+	return nil, errors.Errorf("tx %v was not mined in %v blocks", hash.Hex(), maxMineBlocks)
+}
+
+func (api *ContractApi) minedReceipt(hash common.Hash) *MinedTxReceipt {
+	tx, idx := api.bc.GetTx(hash)
+	if tx == nil || idx == nil {
+		return nil
+	}
+	block := api.bc.GetBlock(idx.BlockHash)
+	receipt := api.bc.GetReceipt(hash)
+	if block == nil || receipt == nil {
+		return nil
+	}
+	result := convertReceipt(tx, receipt, block.Header.FeePerGas(), api.baseApi.blockContracts(block.Height()))
+	result.DebugOutput = api.bc.DebugOutputs().Get(hash)
+	api.baseApi.decodeReceipt(result, api.baseApi.getReadonlyAppState())
+	return &MinedTxReceipt{
+		TxReceipt:   result,
+		BlockHeight: block.Height(),
+		BlockHash:   block.Hash(),
+		TxIndex:     idx.Idx,
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/idena-network/idena-contract-runner/coverage"
+	"github.com/pkg/errors"
+)
+
+type CoverageApi struct {
+	recorder *coverage.Recorder
+}
+
+func NewCoverageApi(recorder *coverage.Recorder) *CoverageApi {
+	return &CoverageApi{recorder: recorder}
+}
+
+// Report returns accumulated hit counts of exported wasm contract functions, format is "json" (default). Internal
+// functions are only counted, and there is no line data, so LCOV tracefiles are not supported.
+func (api *CoverageApi) Report(format string) (interface{}, error) {
+	switch format {
+	case "", "json":
+		return api.recorder.Report(), nil
+	default:
+		return nil, errors.Errorf("unknown coverage format: \"%v\"", format)
+	}
+}
+
+func (api *CoverageApi) Reset() {
+	api.recorder.Reset()
+}
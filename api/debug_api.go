@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-contract-runner/metrics"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"sort"
+)
+
+type DebugApi struct {
+	baseApi *BaseApi
+	bc      *chain.MemBlockchain
+}
+
+func NewDebugApi(baseApi *BaseApi, bc *chain.MemBlockchain) *DebugApi {
+	return &DebugApi{baseApi: baseApi, bc: bc}
+}
+
+type ReplayResult struct {
+	BlockHeight  uint64             `json:"blockHeight"`
+	BlockHash    common.Hash        `json:"blockHash"`
+	TxIndex      uint32             `json:"txIndex"`
+	Receipt      *TxReceipt         `json:"receipt"`
+	StorageDiffs []*StorageDiff     `json:"storageDiffs"`
+	BalanceDiffs []*BalanceDiff     `json:"balanceDiffs"`
+	GasProfile   []*GasProfileEntry `json:"gasProfile"`
+}
+
+type StorageDiff struct {
+	Contract common.Address `json:"contract"`
+	Key      hexutil.Bytes  `json:"key"`
+	Before   hexutil.Bytes  `json:"before"`
+	After    hexutil.Bytes  `json:"after"`
+}
+
+type BalanceDiff struct {
+	Address common.Address  `json:"address"`
+	Before  decimal.Decimal `json:"before"`
+	After   decimal.Decimal `json:"after"`
+}
+
+type GasProfileEntry struct {
+	Depth    int            `json:"depth"`
+	Contract common.Address `json:"contract"`
+	Method   string         `json:"method"`
+	GasUsed  uint64         `json:"gasUsed"`
+	// gas used by the action itself, without the sub actions
+	SelfGasUsed uint64 `json:"selfGasUsed"`
+	Success     bool   `json:"success"`
+	Error       string `json:"error"`
+}
+
+// ReplayTransaction rebuilds the state the mined transaction was executed on, i.e. the state of the parent block
+// with the preceding transactions of the block applied, and executes the transaction again collecting storage and
+// balance diffs and gas used by every action. The transaction runs with the gas limit it is mined with, so the replay
+// fails out of gas like the mined transaction.
+func (api *DebugApi) ReplayTransaction(hash common.Hash) (*ReplayResult, error) {
+	tx, idx := api.bc.GetTx(hash)
+	if tx == nil {
+		if api.baseApi.txpool.GetTx(hash) != nil {
+			return nil, errors.New("transaction is not mined yet")
+		}
+		return nil, errors.New("transaction not found")
+	}
+	block := api.bc.GetBlock(idx.BlockHash)
+	if block == nil {
+		return nil, errors.Errorf("block %v not found", idx.BlockHash.Hex())
+	}
+
+	appState, err := api.stateBeforeTx(block, idx.Idx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the mined receipt tells which contracts the tx touches, their storage and balances are read before execution
+	sender, _ := types.Sender(tx)
+	addresses := []common.Address{sender}
+	if tx.To != nil {
+		addresses = append(addresses, *tx.To)
+	}
+	contracts := make(map[common.Address]struct{})
+	if minedReceipt := api.bc.GetReceipt(hash); minedReceipt != nil {
+		if !minedReceipt.ContractAddress.IsEmpty() {
+			contracts[minedReceipt.ContractAddress] = struct{}{}
+		}
+		if actionResult := convertActionResultBytes(minedReceipt.ActionResult); actionResult != nil {
+			collectContracts(actionResult, contracts)
+		}
+	}
+	for contract := range contracts {
+		addresses = append(addresses, contract)
+	}
+	before := takeStateSnapshot(addresses, contracts, appState)
+
+	feePerGas := appState.State.FeePerGas()
+	receipt, output, err := applyTxOnState(api.bc, appState, block.Header, tx, sender, false, metrics.VmReplay)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplayResult{
+		BlockHeight: block.Height(),
+		BlockHash:   block.Hash(),
+		TxIndex:     idx.Idx,
+		Receipt:     convertReceipt(tx, receipt, feePerGas, stateContracts(appState)),
+	}
+	result.Receipt.DebugOutput = output
+	api.baseApi.decodeReceipt(result.Receipt, appState)
+
+	if result.Receipt.ActionResult != nil {
+		result.GasProfile = buildGasProfile(result.Receipt.ActionResult, 0, nil)
+		replayed := make(map[common.Address]struct{})
+		collectContracts(result.Receipt.ActionResult, replayed)
+		for contract := range replayed {
+			if _, ok := contracts[contract]; !ok {
+				return nil, errors.Errorf("replay of tx %v touched contract %v missing in the mined receipt", hash.Hex(), contract.Hex())
+			}
+		}
+	}
+	for contract := range contracts {
+		result.StorageDiffs = append(result.StorageDiffs, storageDiff(contract, before.storage[contract], appState)...)
+	}
+	result.BalanceDiffs = balanceDiffs(addresses, before.balances, appState)
+	return result, nil
+}
+
+func (api *DebugApi) stateBeforeTx(block *types.Block, txIdx uint32) (*appstate.AppState, error) {
+	parentHeight := block.Height() - 1
+	appState, err := api.bc.AppStateForCheckAt(parentHeight)
+	if err != nil {
+		return nil, errors.Wrapf(err, "state of block %v is not available", parentHeight)
+	}
+	for i := uint32(0); i < txIdx && int(i) < len(block.Body.Transactions); i++ {
+		prevTx := block.Body.Transactions[i]
+		sender, _ := types.Sender(prevTx)
+		if _, _, err := applyTxOnState(api.bc, appState, block.Header, prevTx, sender, false, metrics.VmReplay); err != nil {
+			return nil, errors.Wrapf(err, "replaying tx %v", prevTx.Hash().Hex())
+		}
+	}
+	return appState, nil
+}
+
+func buildGasProfile(actionResult *ActionResult, depth int, profile []*GasProfileEntry) []*GasProfileEntry {
+	entry := &GasProfileEntry{
+		Depth:       depth,
+		Contract:    actionResult.Contract,
+		Method:      actionResult.InputAction.Method,
+		GasUsed:     actionResult.GasUsed,
+		SelfGasUsed: actionResult.GasUsed,
+		Success:     actionResult.Success,
+		Error:       actionResult.Error,
+	}
+	profile = append(profile, entry)
+	for _, subAction := range actionResult.SubActionResults {
+		if entry.SelfGasUsed >= subAction.GasUsed {
+			entry.SelfGasUsed -= subAction.GasUsed
+		}
+		profile = buildGasProfile(subAction, depth+1, profile)
+	}
+	return profile
+}
+
+func collectContracts(actionResult *ActionResult, contracts map[common.Address]struct{}) {
+	contracts[actionResult.Contract] = struct{}{}
+	for _, subAction := range actionResult.SubActionResults {
+		collectContracts(subAction, contracts)
+	}
+}
+
+func contractStorage(contract common.Address, appState *appstate.AppState) map[string][]byte {
+	result := make(map[string][]byte)
+	appState.State.IterateContractStore(contract, nil, nil, func(key []byte, value []byte) bool {
+		result[string(key)] = value
+		return false
+	})
+	return result
+}
+
+// stateSnapshot keeps balances and contract storage read before the replayed tx is executed
+type stateSnapshot struct {
+	balances map[common.Address]*big.Int
+	storage  map[common.Address]map[string][]byte
+}
+
+func takeStateSnapshot(addresses []common.Address, contracts map[common.Address]struct{}, appState *appstate.AppState) *stateSnapshot {
+	snapshot := &stateSnapshot{
+		balances: make(map[common.Address]*big.Int),
+		storage:  make(map[common.Address]map[string][]byte),
+	}
+	for _, addr := range addresses {
+		snapshot.balances[addr] = new(big.Int).Set(appState.State.GetBalance(addr))
+	}
+	for contract := range contracts {
+		snapshot.storage[contract] = contractStorage(contract, appState)
+	}
+	return snapshot
+}
+
+func storageDiff(contract common.Address, prev map[string][]byte, after *appstate.AppState) []*StorageDiff {
+	next := contractStorage(contract, after)
+	var result []*StorageDiff
+	for key, value := range next {
+		if prevValue, ok := prev[key]; !ok || !bytes.Equal(prevValue, value) {
+			result = append(result, &StorageDiff{Contract: contract, Key: []byte(key), Before: prevValue, After: value})
+		}
+	}
+	for key, value := range prev {
+		if _, ok := next[key]; !ok {
+			result = append(result, &StorageDiff{Contract: contract, Key: []byte(key), Before: value})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].Key, result[j].Key) < 0
+	})
+	return result
+}
+
+func balanceDiffs(addresses []common.Address, before map[common.Address]*big.Int, after *appstate.AppState) []*BalanceDiff {
+	var result []*BalanceDiff
+	seen := make(map[common.Address]struct{})
+	for _, addr := range addresses {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		prev, next := before[addr], after.State.GetBalance(addr)
+		if prev.Cmp(next) != 0 {
+			result = append(result, &BalanceDiff{
+				Address: addr,
+				Before:  blockchain.ConvertToFloat(prev),
+				After:   blockchain.ConvertToFloat(next),
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// DnaApi mirrors the dna namespace of idena-go, so wallets and sdk tools can use the runner as a node
+type DnaApi struct {
+	baseApi    *BaseApi
+	bc         *chain.MemBlockchain
+	appVersion string
+}
+
+func NewDnaApi(baseApi *BaseApi, chain *chain.MemBlockchain, appVersion string) *DnaApi {
+	return &DnaApi{
+		baseApi:    baseApi,
+		bc:         chain,
+		appVersion: appVersion,
+	}
+}
+
+type State struct {
+	Name string `json:"name"`
+}
+
+type Balance struct {
+	Stake            decimal.Decimal `json:"stake"`
+	ReplenishedStake decimal.Decimal `json:"replenishedStake"`
+	Balance          decimal.Decimal `json:"balance"`
+	Nonce            uint32          `json:"nonce"`
+	MempoolNonce     uint32          `json:"mempoolNonce"`
+}
+
+// errUseProtoNotSupported is returned by methods signing transactions, the runner always signs the proto encoding and
+// useProto selects only the encoding of bcn_getRawTx
+var errUseProtoNotSupported = errors.New("useProto is supported by bcn_getRawTx only")
+
+type SendTxArgs struct {
+	Type     types.TxType    `json:"type"`
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Amount   decimal.Decimal `json:"amount"`
+	MaxFee   decimal.Decimal `json:"maxFee"`
+	Payload  *hexutil.Bytes  `json:"payload"`
+	Tips     decimal.Decimal `json:"tips"`
+	UseProto bool            `json:"useProto"`
+	BaseTxArgs
+}
+
+type Inviter struct {
+	TxHash      common.Hash    `json:"txHash"`
+	Address     common.Address `json:"address"`
+	EpochHeight uint32         `json:"epochHeight"`
+}
+
+type Flip struct {
+	Hash string `json:"hash"`
+	Pair uint8  `json:"pair"`
+}
+
+// Identity has the fields of the idena-go identity, flip key words are not generated by the runner
+type Identity struct {
+	Address             common.Address  `json:"address"`
+	ProfileHash         string          `json:"profileHash"`
+	Stake               decimal.Decimal `json:"stake"`
+	ReplenishedStake    decimal.Decimal `json:"replenishedStake"`
+	Invites             uint8           `json:"invites"`
+	Age                 uint16          `json:"age"`
+	State               string          `json:"state"`
+	PubKey              string          `json:"pubkey"`
+	RequiredFlips       uint8           `json:"requiredFlips"`
+	AvailableFlips      uint8           `json:"availableFlips"`
+	MadeFlips           uint8           `json:"madeFlips"`
+	QualifiedFlips      uint32          `json:"totalQualifiedFlips"`
+	ShortFlipPoints     float32         `json:"totalShortFlipPoints"`
+	Flips               []string        `json:"flips"`
+	FlipsWithPair       []Flip          `json:"flipsWithPair"`
+	Online              bool            `json:"online"`
+	Generation          uint32          `json:"generation"`
+	Code                hexutil.Bytes   `json:"code"`
+	Invitees            []state.TxAddr  `json:"invitees"`
+	Penalty             decimal.Decimal `json:"penalty"`
+	LastValidationFlags []string        `json:"lastValidationFlags"`
+	Delegatee           *common.Address `json:"delegatee"`
+	DelegationEpoch     uint16          `json:"delegationEpoch"`
+	DelegationNonce     uint32          `json:"delegationNonce"`
+	UndelegationEpoch   uint16          `json:"undelegationEpoch"`
+	PendingUndelegation *common.Address `json:"pendingUndelegation"`
+	IsPool              bool            `json:"isPool"`
+	Inviter             *Inviter        `json:"inviter"`
+	ShardId             uint32          `json:"shardId"`
+}
+
+type Epoch struct {
+	StartBlock     uint64    `json:"startBlock"`
+	Epoch          uint16    `json:"epoch"`
+	NextValidation time.Time `json:"nextValidation"`
+	CurrentPeriod  string    `json:"currentPeriod"`
+}
+
+type CeremonyIntervals struct {
+	FlipLotteryDuration  float64
+	ShortSessionDuration float64
+	LongSessionDuration  float64
+}
+
+type GlobalState struct {
+	NetworkSize int `json:"networkSize"`
+}
+
+type SignatureAddressArgs struct {
+	Value     string
+	Signature hexutil.Bytes
+}
+
+var identityStateNames = map[state.IdentityState]string{
+	state.Invite:    "Invite",
+	state.Candidate: "Candidate",
+	state.Newbie:    "Newbie",
+	state.Verified:  "Verified",
+	state.Suspended: "Suspended",
+	state.Zombie:    "Zombie",
+	state.Killed:    "Killed",
+	state.Human:     "Human",
+}
+
+var validationPeriodNames = map[state.ValidationPeriod]string{
+	state.NonePeriod:             "None",
+	state.FlipLotteryPeriod:      "FlipLottery",
+	state.ShortSessionPeriod:     "ShortSession",
+	state.LongSessionPeriod:      "LongSession",
+	state.AfterLongSessionPeriod: "AfterLongSession",
+}
+
+// State always reports a synchronized node, blocks are produced on demand
+func (api *DnaApi) State() State {
+	return State{Name: "Idle"}
+}
+
+func (api *DnaApi) GetCoinbaseAddr() common.Address {
+	return api.baseApi.getCurrentCoinbase()
+}
+
+func (api *DnaApi) GetBalance(address common.Address) Balance {
+	appState := api.baseApi.getReadonlyAppState()
+	currentEpoch := appState.State.Epoch()
+	nonce, epoch := appState.State.GetNonce(address), appState.State.GetEpoch(address)
+	if epoch < currentEpoch {
+		nonce = 0
+	}
+	return Balance{
+		Stake:            blockchain.ConvertToFloat(appState.State.GetStakeBalance(address)),
+		ReplenishedStake: blockchain.ConvertToFloat(appState.State.GetReplenishedStakeBalance(address)),
+		Balance:          blockchain.ConvertToFloat(appState.State.GetBalance(address)),
+		Nonce:            nonce,
+		MempoolNonce:     appState.NonceCache.GetNonce(address, currentEpoch),
+	}
+}
+
+func (api *DnaApi) SendTransaction(ctx context.Context, args SendTxArgs) (common.Hash, error) {
+	if args.UseProto {
+		return common.Hash{}, errUseProtoNotSupported
+	}
+	var payload []byte
+	if args.Payload != nil {
+		payload = *args.Payload
+	}
+	return api.baseApi.sendTx(ctx, args.From, args.To, args.Type, args.Amount, args.MaxFee, args.Tips, args.Nonce, args.Epoch, payload, nil)
+}
+
+func (api *DnaApi) Identities() []Identity {
+	var identities []Identity
+	appState := api.baseApi.getReadonlyAppState()
+	epoch := appState.State.Epoch()
+	appState.State.IterateIdentities(func(key []byte, value []byte) bool {
+		if key == nil {
+			return true
+		}
+		addr := common.Address{}
+		addr.SetBytes(key[1:])
+		var data state.Identity
+		if err := data.FromBytes(value); err != nil {
+			return false
+		}
+		identities = append(identities, convertIdentity(epoch, addr, data, appState))
+		return false
+	})
+	return identities
+}
+
+func (api *DnaApi) Identity(address *common.Address) Identity {
+	if address == nil {
+		coinbase := api.GetCoinbaseAddr()
+		address = &coinbase
+	}
+	appState := api.baseApi.getReadonlyAppState()
+	return convertIdentity(appState.State.Epoch(), *address, appState.State.GetIdentity(*address), appState)
+}
+
+func convertIdentity(currentEpoch uint16, address common.Address, data state.Identity, appState *appstate.AppState) Identity {
+	stateName, ok := identityStateNames[data.State]
+	if !ok {
+		stateName = "Undefined"
+	}
+
+	var flags []string
+	if data.LastValidationStatus.HasFlag(state.AllFlipsNotQualified) {
+		flags = append(flags, "AllFlipsNotQualified")
+	}
+	if data.LastValidationStatus.HasFlag(state.AtLeastOneFlipNotQualified) {
+		flags = append(flags, "AtLeastOneFlipNotQualified")
+	}
+	if data.LastValidationStatus.HasFlag(state.AtLeastOneFlipReported) {
+		flags = append(flags, "AtLeastOneFlipReported")
+	}
+
+	var profileHash string
+	if len(data.ProfileHash) > 0 {
+		c, _ := cid.Parse(data.ProfileHash)
+		profileHash = c.String()
+	}
+
+	var flipHashes []string
+	var flips []Flip
+	for _, v := range data.Flips {
+		c, _ := cid.Parse(v.Cid)
+		flipHashes = append(flipHashes, c.String())
+		flips = append(flips, Flip{Hash: c.String(), Pair: v.Pair})
+	}
+
+	var invitees []state.TxAddr
+	if len(data.Invitees) > 0 {
+		invitees = data.Invitees
+	}
+
+	age := uint16(0)
+	if data.State.NewbieOrBetter() || data.State == state.Suspended || data.State == state.Zombie {
+		age = currentEpoch - data.Birthday
+	}
+
+	totalPoints, totalFlips := common.CalculateIdentityScores(data.Scores, data.GetShortFlipPoints(), data.QualifiedFlips)
+
+	isOnline := appState.ValidatorsCache.IsOnlineIdentity(address)
+	if appState.State.HasStatusSwitchAddresses(address) {
+		isOnline = !isOnline
+	}
+	if appState.State.HasDelayedOfflinePenalty(address) {
+		isOnline = false
+	}
+
+	delegatee := data.Delegatee()
+	if switchDelegation := appState.State.DelegationSwitch(address); switchDelegation != nil {
+		if switchDelegation.Delegatee.IsEmpty() {
+			delegatee = nil
+		} else {
+			delegatee = &switchDelegation.Delegatee
+		}
+	}
+
+	var inviter *Inviter
+	if data.Inviter != nil {
+		inviter = &Inviter{
+			TxHash:      data.Inviter.TxHash,
+			Address:     data.Inviter.Address,
+			EpochHeight: data.Inviter.EpochHeight,
+		}
+	}
+
+	return Identity{
+		Address:             address,
+		State:               stateName,
+		Stake:               blockchain.ConvertToFloat(data.Stake),
+		ReplenishedStake:    blockchain.ConvertToFloat(data.ReplenishedStake()),
+		Age:                 age,
+		Invites:             data.Invites,
+		ProfileHash:         profileHash,
+		PubKey:              fmt.Sprintf("%x", data.PubKey),
+		RequiredFlips:       data.RequiredFlips,
+		AvailableFlips:      data.GetMaximumAvailableFlips(),
+		MadeFlips:           uint8(len(data.Flips)),
+		QualifiedFlips:      totalFlips,
+		ShortFlipPoints:     totalPoints,
+		Flips:               flipHashes,
+		FlipsWithPair:       flips,
+		Generation:          data.Generation,
+		Code:                data.Code,
+		Invitees:            invitees,
+		Penalty:             blockchain.ConvertToFloat(data.Penalty),
+		LastValidationFlags: flags,
+		Delegatee:           delegatee,
+		DelegationEpoch:     data.DelegationEpoch,
+		UndelegationEpoch:   data.UndelegationEpoch(),
+		DelegationNonce:     data.DelegationNonce,
+		Online:              isOnline,
+		IsPool:              appState.ValidatorsCache.IsPool(address),
+		Inviter:             inviter,
+		ShardId:             uint32(data.ShiftedShardId()),
+	}
+}
+
+func (api *DnaApi) Epoch() Epoch {
+	appState := api.baseApi.getReadonlyAppState()
+	return Epoch{
+		Epoch:          appState.State.Epoch(),
+		StartBlock:     appState.State.EpochBlock(),
+		NextValidation: appState.State.NextValidationTime(),
+		CurrentPeriod:  validationPeriodNames[appState.State.ValidationPeriod()],
+	}
+}
+
+func (api *DnaApi) CeremonyIntervals() CeremonyIntervals {
+	cfg := api.bc.Config()
+	networkSize := api.baseApi.getReadonlyAppState().ValidatorsCache.NetworkSize()
+	return CeremonyIntervals{
+		FlipLotteryDuration:  cfg.Validation.GetFlipLotteryDuration().Seconds(),
+		ShortSessionDuration: cfg.Validation.GetShortSessionDuration().Seconds(),
+		LongSessionDuration:  cfg.Validation.GetLongSessionDuration(networkSize).Seconds(),
+	}
+}
+
+func (api *DnaApi) GlobalState() GlobalState {
+	return GlobalState{
+		NetworkSize: api.baseApi.getReadonlyAppState().ValidatorsCache.NetworkSize(),
+	}
+}
+
+func (api *DnaApi) IsValidationReady() bool {
+	return false
+}
+
+func (api *DnaApi) Version() string {
+	return api.appVersion
+}
+
+func (api *DnaApi) Sign(value string) hexutil.Bytes {
+	hash := signatureHash(value)
+	return api.baseApi.secStore.Sign(hash[:])
+}
+
+func (api *DnaApi) SignatureAddress(args SignatureAddressArgs) (common.Address, error) {
+	hash := signatureHash(args.Value)
+	pubKey, err := crypto.Ecrecover(hash[:], args.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubKeyBytesToAddress(pubKey)
+}
+
+func signatureHash(value string) common.Hash {
+	h := crypto.Hash([]byte(value))
+	return crypto.Hash(h[:])
+}
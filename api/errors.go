@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-contract-runner/wasm"
+	"github.com/idena-network/idena-go/blockchain/attachments"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/blockchain/validation"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/mempool"
+	models "github.com/idena-network/idena-wasm-binding/lib/protobuf"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"sync"
+)
+
+// Error codes of rejected transactions and failed contract executions
+const (
+	ErrCodeTxRejected          = -32010
+	ErrCodeInsufficientFunds   = -32011
+	ErrCodeInvalidNonce        = -32012
+	ErrCodeMaxFeeTooLow        = -32013
+	ErrCodeMaxFeeTooHigh       = -32014
+	ErrCodeDuplicateTx         = -32015
+	ErrCodeMempoolFull         = -32016
+	ErrCodeInvalidDeployAmount = -32017
+
+	ErrCodeContractError    = -32020
+	ErrCodeContractNotFound = -32021
+	ErrCodeMethodNotFound   = -32022
+	ErrCodeOutOfGas         = -32023
+	ErrCodeContractPanic    = -32024
+)
+
+type ErrorData struct {
+	Kind  string `json:"kind"`
+	Cause string `json:"cause"`
+}
+
+// RpcError keeps the message of the original error and adds a stable code and a data payload
+type RpcError struct {
+	Code    int
+	Message string
+	Data    *ErrorData
+}
+
+func (e *RpcError) Error() string {
+	return e.Message
+}
+
+func (e *RpcError) ErrorCode() int {
+	return e.Code
+}
+
+func (e *RpcError) ErrorData() interface{} {
+	return e.Data
+}
+
+type errorKind struct {
+	code int
+	kind string
+}
+
+var (
+	contractErrorKind    = errorKind{ErrCodeContractError, "contractError"}
+	contractNotFoundKind = errorKind{ErrCodeContractNotFound, "contractNotFound"}
+	methodNotFoundKind   = errorKind{ErrCodeMethodNotFound, "methodNotFound"}
+	outOfGasKind         = errorKind{ErrCodeOutOfGas, "outOfGas"}
+	contractPanicKind    = errorKind{ErrCodeContractPanic, "contractPanic"}
+)
+
+var txErrorKinds = map[error]errorKind{
+	validation.InsufficientFunds:   {ErrCodeInsufficientFunds, "insufficientFunds"},
+	validation.InvalidNonce:        {ErrCodeInvalidNonce, "invalidNonce"},
+	validation.InvalidEpoch:        {ErrCodeInvalidNonce, "invalidEpoch"},
+	validation.BigFee:              {ErrCodeMaxFeeTooLow, "maxFeeTooLow"},
+	validation.InvalidMaxFee:       {ErrCodeMaxFeeTooLow, "maxFeeTooLow"},
+	validation.TooHighMaxFee:       {ErrCodeMaxFeeTooHigh, "maxFeeTooHigh"},
+	validation.DuplicatedTx:        {ErrCodeDuplicateTx, "duplicateTx"},
+	mempool.DuplicateTxError:       {ErrCodeDuplicateTx, "duplicateTx"},
+	mempool.MempoolFullError:       {ErrCodeMempoolFull, "mempoolFull"},
+	validation.InvalidDeployAmount: {ErrCodeInvalidDeployAmount, "invalidDeployAmount"},
+}
+
+type errorCollectorKey struct{}
+
+// ErrorCollector keeps structured errors returned by api methods of a http request in the order they are returned.
+// The rpc server passes only messages of returned errors to the codec, error responses take codes and data from here.
+type ErrorCollector struct {
+	lock   sync.Mutex
+	errors []*RpcError
+}
+
+// WithErrorCollector returns the context api methods of a http request report their structured errors to
+func WithErrorCollector(ctx context.Context, collector *ErrorCollector) context.Context {
+	return context.WithValue(ctx, errorCollectorKey{}, collector)
+}
+
+func errorCollector(ctx context.Context) *ErrorCollector {
+	collector, _ := ctx.Value(errorCollectorKey{}).(*ErrorCollector)
+	return collector
+}
+
+func (c *ErrorCollector) add(err *RpcError) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.errors = append(c.errors, err)
+}
+
+// Empty reports whether no structured errors are reported
+func (c *ErrorCollector) Empty() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.errors) == 0
+}
+
+// Take returns the first reported error with the message for the next error response of the request, errors reported
+// before it are not returned by their methods and are dropped. Nil is returned if the error is not structured.
+func (c *ErrorCollector) Take(message string) *RpcError {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, err := range c.errors {
+		if err.Message == message {
+			c.errors = c.errors[i+1:]
+			return err
+		}
+	}
+	return nil
+}
+
+func newRpcError(ctx context.Context, err error, kind errorKind) *RpcError {
+	result := &RpcError{
+		Code:    kind.code,
+		Message: err.Error(),
+		Data:    &ErrorData{Kind: kind.kind, Cause: errors.Cause(err).Error()},
+	}
+	if collector := errorCollector(ctx); collector != nil {
+		collector.add(result)
+	}
+	return result
+}
+
+// txRejectedError classifies an error of the mempool or the tx validation
+func txRejectedError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	kind, ok := txErrorKinds[errors.Cause(err)]
+	if !ok {
+		kind = errorKind{ErrCodeTxRejected, "txRejected"}
+	}
+	return newRpcError(ctx, err, kind)
+}
+
+// contractError classifies an error of a readonly contract call
+func contractError(ctx context.Context, err error, appState *appstate.AppState, contract common.Address, method string) error {
+	if err == nil {
+		return nil
+	}
+	code, deployed := stateContracts(appState)(contract)
+	return newRpcError(ctx, err, executionErrorKind(code, deployed, method, nil))
+}
+
+// contractLookup returns the code of the contract and whether the contract is deployed, embedded contracts have no code
+type contractLookup func(contract common.Address) (code []byte, deployed bool)
+
+func stateContracts(appState *appstate.AppState) contractLookup {
+	return func(contract common.Address) ([]byte, bool) {
+		if appState.State.GetCodeHash(contract) == nil {
+			return nil, false
+		}
+		return appState.State.GetContractCode(contract), true
+	}
+}
+
+// receiptErrorKind classifies the error of a contract tx receipt, the code of a deployed contract comes from the tx
+// and the code of a called one from contracts
+func receiptErrorKind(tx *types.Transaction, receipt *types.TxReceipt, contracts contractLookup) errorKind {
+	var actionResult *models.ActionResult
+	if len(receipt.ActionResult) > 0 {
+		actionResult = &models.ActionResult{}
+		if err := proto.Unmarshal(receipt.ActionResult, actionResult); err != nil {
+			actionResult = nil
+		}
+	}
+	if tx.Type == types.DeployContractTx {
+		attach := attachments.ParseDeployContractAttachment(tx)
+		if attach == nil {
+			return contractErrorKind
+		}
+		return executionErrorKind(attach.Code, true, "", actionResult)
+	}
+	if contracts == nil {
+		return contractErrorKind
+	}
+	code, deployed := contracts(receipt.ContractAddress)
+	return executionErrorKind(code, deployed, receipt.Method, actionResult)
+}
+
+// executionErrorKind classifies a failed execution by the state of the contract and the result of the execution: the
+// missing contract, the method missing in wasm exports, the exhausted gas, and the trap of the wasm code otherwise.
+// Embedded contracts fail with contract errors.
+func executionErrorKind(code []byte, deployed bool, method string, actionResult *models.ActionResult) errorKind {
+	if actionResult != nil && !actionResult.Success && actionResult.GasUsed > 0 && actionResult.RemainingGas == 0 {
+		return outOfGasKind
+	}
+	if !deployed {
+		return contractNotFoundKind
+	}
+	if len(code) == 0 {
+		return contractErrorKind
+	}
+	module, err := wasm.ParseModule(code)
+	if err != nil {
+		return contractErrorKind
+	}
+	if method != "" {
+		exported := false
+		for _, name := range module.ExportedFunctions() {
+			if name == method {
+				exported = true
+				break
+			}
+		}
+		if !exported {
+			return methodNotFoundKind
+		}
+	}
+	return contractPanicKind
+}
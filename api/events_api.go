@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
+	"github.com/idena-network/idena-go/events"
+	"github.com/idena-network/idena-go/log"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/shopspring/decimal"
+)
+
+// notifications are buffered per subscription, a slow client loses notifications instead of blocking the chain
+const subscriptionBufferSize = 1000
+
+// EventsApi provides websocket subscriptions, e.g. {"method":"events_subscribe","params":["newBlocks"]}
+type EventsApi struct {
+	baseApi *BaseApi
+	bc      *chain.MemBlockchain
+}
+
+func NewEventsApi(baseApi *BaseApi, bc *chain.MemBlockchain) *EventsApi {
+	return &EventsApi{baseApi: baseApi, bc: bc}
+}
+
+type SubscriptionFilter struct {
+	Contract *common.Address `json:"contract"`
+	Event    string          `json:"event"`
+}
+
+type BlockNotification struct {
+	Height     uint64         `json:"height"`
+	Hash       common.Hash    `json:"hash"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Timestamp  int64          `json:"timestamp"`
+	Coinbase   common.Address `json:"coinbase"`
+	Txs        []common.Hash  `json:"txs"`
+}
+
+type PendingTxNotification struct {
+	Hash   common.Hash     `json:"hash"`
+	Type   types.TxType    `json:"type"`
+	From   common.Address  `json:"from"`
+	To     *common.Address `json:"to"`
+	Amount decimal.Decimal `json:"amount"`
+	Nonce  uint32          `json:"nonce"`
+}
+
+type ReceiptNotification struct {
+	BlockHeight uint64      `json:"blockHeight"`
+	BlockHash   common.Hash `json:"blockHash"`
+	TxIndex     uint32      `json:"txIndex"`
+	*TxReceipt
+}
+
+// NewBlocks notifies about every added block
+func (api *EventsApi) NewBlocks(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, events.AddBlockEventID, func(e eventbus.Event, send func(interface{})) {
+		block := e.(*events.NewBlockEvent).Block
+		notification := &BlockNotification{
+			Height:     block.Height(),
+			Hash:       block.Hash(),
+			ParentHash: block.Header.ParentHash(),
+			Timestamp:  block.Header.Time(),
+			Coinbase:   block.Header.Coinbase(),
+			Txs:        make([]common.Hash, 0, len(block.Body.Transactions)),
+		}
+		for _, tx := range block.Body.Transactions {
+			notification.Txs = append(notification.Txs, tx.Hash())
+		}
+		send(notification)
+	})
+}
+
+// PendingTransactions notifies about transactions accepted by the mempool
+func (api *EventsApi) PendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, events.NewTxEventID, func(e eventbus.Event, send func(interface{})) {
+		tx := e.(*events.NewTxEvent).Tx
+		sender, _ := types.Sender(tx)
+		send(&PendingTxNotification{
+			Hash:   tx.Hash(),
+			Type:   tx.Type,
+			From:   sender,
+			To:     tx.To,
+			Amount: blockchain.ConvertToFloat(tx.AmountOrZero()),
+			Nonce:  tx.AccountNonce,
+		})
+	})
+}
+
+// Receipts notifies about receipts of mined transactions, optionally only of the ones executed by the contract
+func (api *EventsApi) Receipts(ctx context.Context, filter *SubscriptionFilter) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, events.AddBlockEventID, func(e eventbus.Event, send func(interface{})) {
+		block := e.(*events.NewBlockEvent).Block
+		appState := api.baseApi.getReadonlyAppState()
+		for i, tx := range block.Body.Transactions {
+			receipt := api.bc.GetReceipt(tx.Hash())
+			if receipt == nil {
+				continue
+			}
+			if filter != nil && filter.Contract != nil && receipt.ContractAddress != *filter.Contract {
+				continue
+			}
+			result := convertReceipt(tx, receipt, block.Header.FeePerGas(), api.baseApi.blockContracts(block.Height()))
+			result.DebugOutput = api.bc.DebugOutputs().Get(tx.Hash())
+			api.baseApi.decodeReceipt(result, appState)
+			send(&ReceiptNotification{
+				BlockHeight: block.Height(),
+				BlockHash:   block.Hash(),
+				TxIndex:     uint32(i),
+				TxReceipt:   result,
+			})
+		}
+	})
+}
+
+// ContractEvents notifies about events of mined transactions filtered by contract and event name
+func (api *EventsApi) ContractEvents(ctx context.Context, filter *SubscriptionFilter) (*rpc.Subscription, error) {
+	matcher := &eventMatcher{}
+	if filter != nil {
+		matcher.contract, matcher.event = filter.Contract, filter.Event
+	}
+	return api.subscribe(ctx, events.AddBlockEventID, func(e eventbus.Event, send func(interface{})) {
+		block := e.(*events.NewBlockEvent).Block
+		appState := api.baseApi.getReadonlyAppState()
+		var logIndex uint32
+		for i, tx := range block.Body.Transactions {
+			receipt := api.bc.GetReceipt(tx.Hash())
+			if receipt == nil {
+				continue
+			}
+			for _, txEvent := range receipt.Events {
+				event := convertEvent(txEvent, receipt.ContractAddress)
+				position := logIndex
+				logIndex++
+				if !matcher.match(&event) {
+					continue
+				}
+				api.baseApi.decodeEvent(&event, appState)
+				send(&ContractEvent{
+					Event:       event,
+					BlockHeight: block.Height(),
+					BlockHash:   block.Hash(),
+					TxHash:      tx.Hash(),
+					TxIndex:     uint32(i),
+					LogIndex:    position,
+				})
+			}
+		}
+	})
+}
+
+// subscribe forwards the bus events converted by handle to the client until it unsubscribes or disconnects
+func (api *EventsApi) subscribe(ctx context.Context, eventID eventbus.EventID, handle func(e eventbus.Event, send func(interface{}))) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	notifications := make(chan interface{}, subscriptionBufferSize)
+	busSub := api.bc.Bus().Subscribe(eventID, func(e eventbus.Event) {
+		handle(e, func(data interface{}) {
+			select {
+			case notifications <- data:
+			default:
+				log.Warn("Subscription buffer is full, notification dropped", "id", sub.ID)
+			}
+		})
+	})
+	go func() {
+		defer api.bc.Bus().Unsubscribe(busSub)
+		for {
+			select {
+			case data := <-notifications:
+				notifier.Notify(sub.ID, data)
+			case <-sub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
@@ -0,0 +1,338 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/vm/embedded"
+	"github.com/shopspring/decimal"
+	"strconv"
+)
+
+// PredefinedApi wraps the contracts built into idena-go, argument indexes and formats follow their Deploy and Call
+// implementations in the vm/embedded package
+type PredefinedApi struct {
+	contractApi *ContractApi
+}
+
+func NewPredefinedApi(contractApi *ContractApi) *PredefinedApi {
+	return &PredefinedApi{contractApi: contractApi}
+}
+
+type PredefinedTxArgs struct {
+	From   common.Address  `json:"from"`
+	MaxFee decimal.Decimal `json:"maxFee"`
+}
+
+type PredefinedCallArgs struct {
+	PredefinedTxArgs
+	Contract common.Address  `json:"contract"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
+type PredefinedTerminateArgs struct {
+	PredefinedTxArgs
+	Contract common.Address `json:"contract"`
+	// receiver of the contract stake, not used by oracle voting and oracle lock
+	Dest *common.Address `json:"dest"`
+}
+
+type TimeLockDeployArgs struct {
+	PredefinedTxArgs
+	Amount    decimal.Decimal `json:"amount"`
+	Timestamp uint64          `json:"timestamp"`
+}
+
+type TransferArgsWithContract struct {
+	PredefinedCallArgs
+	To    common.Address  `json:"to"`
+	Value decimal.Decimal `json:"value"`
+}
+
+type MultisigDeployArgs struct {
+	PredefinedTxArgs
+	Amount   decimal.Decimal `json:"amount"`
+	MaxVotes byte            `json:"maxVotes"`
+	MinVotes byte            `json:"minVotes"`
+}
+
+type MultisigAddArgs struct {
+	PredefinedCallArgs
+	Address common.Address `json:"address"`
+}
+
+type OracleVotingDeployArgs struct {
+	PredefinedTxArgs
+	Amount               decimal.Decimal  `json:"amount"`
+	Fact                 hexutil.Bytes    `json:"fact"`
+	StartTime            uint64           `json:"startTime"`
+	VotingDuration       *uint64          `json:"votingDuration"`
+	PublicVotingDuration *uint64          `json:"publicVotingDuration"`
+	WinnerThreshold      *byte            `json:"winnerThreshold"`
+	Quorum               *byte            `json:"quorum"`
+	CommitteeSize        *uint64          `json:"committeeSize"`
+	VotingMinPayment     *decimal.Decimal `json:"votingMinPayment"`
+	OwnerFee             *byte            `json:"ownerFee"`
+	OracleRewardFund     *decimal.Decimal `json:"oracleRewardFund"`
+	RefundRecipient      *common.Address  `json:"refundRecipient"`
+}
+
+type OracleVoteProofArgs struct {
+	PredefinedCallArgs
+	VoteHash hexutil.Bytes `json:"voteHash"`
+}
+
+type OracleVoteArgs struct {
+	PredefinedCallArgs
+	Vote byte          `json:"vote"`
+	Salt hexutil.Bytes `json:"salt"`
+}
+
+type OracleLockDeployArgs struct {
+	PredefinedTxArgs
+	Amount       decimal.Decimal `json:"amount"`
+	OracleVoting common.Address  `json:"oracleVoting"`
+	Value        byte            `json:"value"`
+	SuccessAddr  common.Address  `json:"successAddr"`
+	FailAddr     common.Address  `json:"failAddr"`
+}
+
+type RefundableOracleLockDeployArgs struct {
+	PredefinedTxArgs
+	Amount          decimal.Decimal `json:"amount"`
+	OracleVoting    common.Address  `json:"oracleVoting"`
+	Value           byte            `json:"value"`
+	SuccessAddr     *common.Address `json:"successAddr"`
+	FailAddr        *common.Address `json:"failAddr"`
+	RefundDelay     *uint64         `json:"refundDelay"`
+	DepositDeadline uint64          `json:"depositDeadline"`
+	OracleVotingFee uint64          `json:"oracleVotingFee"`
+}
+
+// predefinedArgs collects dynamic args by index, optional args which are not set are left as gaps
+type predefinedArgs DynamicArgs
+
+func (a *predefinedArgs) add(index int, format string, value string) {
+	*a = append(*a, &DynamicArg{Index: index, Format: format, Value: value})
+}
+
+func (a *predefinedArgs) uint64(index int, value uint64) {
+	a.add(index, "uint64", strconv.FormatUint(value, 10))
+}
+
+func (a *predefinedArgs) byte(index int, value byte) {
+	a.add(index, "byte", strconv.Itoa(int(value)))
+}
+
+func (a *predefinedArgs) address(index int, value common.Address) {
+	a.add(index, "hex", value.Hex())
+}
+
+func (a *predefinedArgs) dna(index int, value decimal.Decimal) {
+	a.add(index, "dna", value.String())
+}
+
+func (a *predefinedArgs) bytes(index int, value []byte) {
+	a.add(index, "hex", hexutil.Encode(value))
+}
+
+func (api *PredefinedApi) deploy(ctx context.Context, contractType embedded.EmbeddedContractType, args PredefinedTxArgs, amount decimal.Decimal, dynamicArgs predefinedArgs) (common.Hash, error) {
+	return api.contractApi.Deploy(ctx, DeployArgs{
+		From:     args.From,
+		CodeHash: contractType.Bytes(),
+		Amount:   amount,
+		Args:     DynamicArgs(dynamicArgs),
+		MaxFee:   args.MaxFee,
+	})
+}
+
+func (api *PredefinedApi) call(ctx context.Context, args PredefinedCallArgs, method string, dynamicArgs predefinedArgs) (common.Hash, error) {
+	return api.contractApi.Call(ctx, CallArgs{
+		From:     args.From,
+		Contract: args.Contract,
+		Method:   method,
+		Amount:   args.Amount,
+		Args:     DynamicArgs(dynamicArgs),
+		MaxFee:   args.MaxFee,
+	})
+}
+
+func (api *PredefinedApi) Terminate(ctx context.Context, args PredefinedTerminateArgs) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	if args.Dest != nil {
+		dynamicArgs.address(0, *args.Dest)
+	}
+	return api.contractApi.Terminate(ctx, TerminateArgs{
+		From:     args.From,
+		Contract: args.Contract,
+		Args:     DynamicArgs(dynamicArgs),
+		MaxFee:   args.MaxFee,
+	})
+}
+
+func (api *PredefinedApi) DeployTimeLock(ctx context.Context, args TimeLockDeployArgs) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.uint64(0, args.Timestamp)
+	return api.deploy(ctx, embedded.TimeLockContract, args.PredefinedTxArgs, args.Amount, dynamicArgs)
+}
+
+func (api *PredefinedApi) TimeLockTransfer(ctx context.Context, args TransferArgsWithContract) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.address(0, args.To)
+	dynamicArgs.dna(1, args.Value)
+	return api.call(ctx, args.PredefinedCallArgs, "transfer", dynamicArgs)
+}
+
+func (api *PredefinedApi) DeployMultisig(ctx context.Context, args MultisigDeployArgs) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.byte(0, args.MaxVotes)
+	dynamicArgs.byte(1, args.MinVotes)
+	return api.deploy(ctx, embedded.MultisigContract, args.PredefinedTxArgs, args.Amount, dynamicArgs)
+}
+
+func (api *PredefinedApi) MultisigAdd(ctx context.Context, args MultisigAddArgs) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.address(0, args.Address)
+	return api.call(ctx, args.PredefinedCallArgs, "add", dynamicArgs)
+}
+
+// MultisigSend votes for sending the value to the address
+func (api *PredefinedApi) MultisigSend(ctx context.Context, args TransferArgsWithContract) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.address(0, args.To)
+	dynamicArgs.dna(1, args.Value)
+	return api.call(ctx, args.PredefinedCallArgs, "send", dynamicArgs)
+}
+
+// MultisigPush sends the value to the address if there are enough votes for it
+func (api *PredefinedApi) MultisigPush(ctx context.Context, args TransferArgsWithContract) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.address(0, args.To)
+	dynamicArgs.dna(1, args.Value)
+	return api.call(ctx, args.PredefinedCallArgs, "push", dynamicArgs)
+}
+
+func (api *PredefinedApi) DeployOracleVoting(ctx context.Context, args OracleVotingDeployArgs) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.bytes(0, args.Fact)
+	dynamicArgs.uint64(1, args.StartTime)
+	if args.VotingDuration != nil {
+		dynamicArgs.uint64(2, *args.VotingDuration)
+	}
+	if args.PublicVotingDuration != nil {
+		dynamicArgs.uint64(3, *args.PublicVotingDuration)
+	}
+	if args.WinnerThreshold != nil {
+		dynamicArgs.byte(4, *args.WinnerThreshold)
+	}
+	if args.Quorum != nil {
+		dynamicArgs.byte(5, *args.Quorum)
+	}
+	if args.CommitteeSize != nil {
+		dynamicArgs.uint64(6, *args.CommitteeSize)
+	}
+	if args.VotingMinPayment != nil {
+		dynamicArgs.dna(7, *args.VotingMinPayment)
+	}
+	if args.OwnerFee != nil {
+		dynamicArgs.byte(8, *args.OwnerFee)
+	}
+	if args.OracleRewardFund != nil {
+		dynamicArgs.dna(9, *args.OracleRewardFund)
+	}
+	if args.RefundRecipient != nil {
+		dynamicArgs.address(10, *args.RefundRecipient)
+	}
+	return api.deploy(ctx, embedded.OracleVotingContract, args.PredefinedTxArgs, args.Amount, dynamicArgs)
+}
+
+func (api *PredefinedApi) OracleVotingStartVoting(ctx context.Context, args PredefinedCallArgs) (common.Hash, error) {
+	return api.call(ctx, args, "startVoting", nil)
+}
+
+func (api *PredefinedApi) OracleVotingSendVoteProof(ctx context.Context, args OracleVoteProofArgs) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.bytes(0, args.VoteHash)
+	return api.call(ctx, args.PredefinedCallArgs, "sendVoteProof", dynamicArgs)
+}
+
+func (api *PredefinedApi) OracleVotingSendVote(ctx context.Context, args OracleVoteArgs) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.byte(0, args.Vote)
+	dynamicArgs.bytes(1, args.Salt)
+	return api.call(ctx, args.PredefinedCallArgs, "sendVote", dynamicArgs)
+}
+
+func (api *PredefinedApi) OracleVotingFinishVoting(ctx context.Context, args PredefinedCallArgs) (common.Hash, error) {
+	return api.call(ctx, args, embedded.FinishVotingMethod, nil)
+}
+
+func (api *PredefinedApi) OracleVotingProlongVoting(ctx context.Context, args PredefinedCallArgs) (common.Hash, error) {
+	return api.call(ctx, args, "prolongVoting", nil)
+}
+
+// OracleVotingAddStake moves the amount of the call to the stake of the contract
+func (api *PredefinedApi) OracleVotingAddStake(ctx context.Context, args PredefinedCallArgs) (common.Hash, error) {
+	return api.call(ctx, args, "addStake", nil)
+}
+
+// OracleVotingVoteHash returns the hash to send with the vote proof, the same salt has to be sent with the vote
+func (api *PredefinedApi) OracleVotingVoteHash(ctx context.Context, contract common.Address, vote byte, salt hexutil.Bytes) (interface{}, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.byte(0, vote)
+	dynamicArgs.bytes(1, salt)
+	return api.contractApi.ReadonlyCall(ctx, ReadonlyCallArgs{
+		Contract: contract,
+		Method:   "voteHash",
+		Format:   "hex",
+		Args:     DynamicArgs(dynamicArgs),
+	}, nil)
+}
+
+func (api *PredefinedApi) DeployOracleLock(ctx context.Context, args OracleLockDeployArgs) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.address(0, args.OracleVoting)
+	dynamicArgs.byte(1, args.Value)
+	dynamicArgs.address(2, args.SuccessAddr)
+	dynamicArgs.address(3, args.FailAddr)
+	return api.deploy(ctx, embedded.OracleLockContract, args.PredefinedTxArgs, args.Amount, dynamicArgs)
+}
+
+func (api *PredefinedApi) OracleLockCheckOracleVoting(ctx context.Context, args PredefinedCallArgs) (common.Hash, error) {
+	return api.call(ctx, args, "checkOracleVoting", nil)
+}
+
+func (api *PredefinedApi) OracleLockPush(ctx context.Context, args PredefinedCallArgs) (common.Hash, error) {
+	return api.call(ctx, args, "push", nil)
+}
+
+func (api *PredefinedApi) DeployRefundableOracleLock(ctx context.Context, args RefundableOracleLockDeployArgs) (common.Hash, error) {
+	var dynamicArgs predefinedArgs
+	dynamicArgs.address(0, args.OracleVoting)
+	dynamicArgs.byte(1, args.Value)
+	if args.SuccessAddr != nil {
+		dynamicArgs.address(2, *args.SuccessAddr)
+	}
+	if args.FailAddr != nil {
+		dynamicArgs.address(3, *args.FailAddr)
+	}
+	if args.RefundDelay != nil {
+		dynamicArgs.uint64(4, *args.RefundDelay)
+	}
+	dynamicArgs.uint64(5, args.DepositDeadline)
+	dynamicArgs.uint64(6, args.OracleVotingFee)
+	return api.deploy(ctx, embedded.RefundableOracleLockContract, args.PredefinedTxArgs, args.Amount, dynamicArgs)
+}
+
+// RefundableOracleLockDeposit deposits the amount of the call
+func (api *PredefinedApi) RefundableOracleLockDeposit(ctx context.Context, args PredefinedCallArgs) (common.Hash, error) {
+	return api.call(ctx, args, "deposit", nil)
+}
+
+func (api *PredefinedApi) RefundableOracleLockPush(ctx context.Context, args PredefinedCallArgs) (common.Hash, error) {
+	return api.call(ctx, args, "push", nil)
+}
+
+func (api *PredefinedApi) RefundableOracleLockRefund(ctx context.Context, args PredefinedCallArgs) (common.Hash, error) {
+	return api.call(ctx, args, "refund", nil)
+}
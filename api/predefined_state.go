@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/vm/embedded"
+	"github.com/idena-network/idena-go/vm/helpers"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"math/big"
+)
+
+type TimeLockState struct {
+	Owner     common.Address  `json:"owner"`
+	Timestamp uint64          `json:"timestamp"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+type MultisigVote struct {
+	Address common.Address `json:"address"`
+	// destination and amount the participant voted for, the amount is zero if there is no active vote
+	Dest   common.Address  `json:"dest"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type MultisigState struct {
+	Owner    common.Address  `json:"owner"`
+	MaxVotes byte            `json:"maxVotes"`
+	MinVotes byte            `json:"minVotes"`
+	State    byte            `json:"state"`
+	Count    byte            `json:"count"`
+	Votes    []*MultisigVote `json:"votes"`
+	Balance  decimal.Decimal `json:"balance"`
+}
+
+type OracleVotingState struct {
+	Owner                common.Address   `json:"owner"`
+	State                byte             `json:"state"`
+	Fact                 hexutil.Bytes    `json:"fact"`
+	StartTime            uint64           `json:"startTime"`
+	StartBlock           uint64           `json:"startBlock"`
+	Epoch                uint16           `json:"epoch"`
+	VotingDuration       uint64           `json:"votingDuration"`
+	PublicVotingDuration uint64           `json:"publicVotingDuration"`
+	WinnerThreshold      byte             `json:"winnerThreshold"`
+	Quorum               byte             `json:"quorum"`
+	CommitteeSize        uint64           `json:"committeeSize"`
+	NetworkSize          uint64           `json:"networkSize"`
+	OwnerFee             byte             `json:"ownerFee"`
+	VotingMinPayment     *decimal.Decimal `json:"votingMinPayment"`
+	OwnerDeposit         *decimal.Decimal `json:"ownerDeposit"`
+	OracleRewardFund     *decimal.Decimal `json:"oracleRewardFund"`
+	RefundRecipient      *common.Address  `json:"refundRecipient"`
+	VotedCount           uint64           `json:"votedCount"`
+	Result               *byte            `json:"result"`
+	Balance              decimal.Decimal  `json:"balance"`
+	Stake                decimal.Decimal  `json:"stake"`
+}
+
+type OracleLockState struct {
+	Owner                  common.Address  `json:"owner"`
+	OracleVoting           common.Address  `json:"oracleVoting"`
+	Value                  byte            `json:"value"`
+	SuccessAddr            common.Address  `json:"successAddr"`
+	FailAddr               common.Address  `json:"failAddr"`
+	IsOracleVotingFinished bool            `json:"isOracleVotingFinished"`
+	Voted                  *byte           `json:"voted"`
+	Balance                decimal.Decimal `json:"balance"`
+}
+
+type RefundableOracleLockState struct {
+	Owner           common.Address  `json:"owner"`
+	OracleVoting    common.Address  `json:"oracleVoting"`
+	Value           byte            `json:"value"`
+	SuccessAddr     *common.Address `json:"successAddr"`
+	FailAddr        *common.Address `json:"failAddr"`
+	RefundDelay     uint64          `json:"refundDelay"`
+	RefundBlock     uint64          `json:"refundBlock"`
+	DepositDeadline uint64          `json:"depositDeadline"`
+	OracleVotingFee uint64          `json:"oracleVotingFee"`
+	State           byte            `json:"state"`
+	Sum             decimal.Decimal `json:"sum"`
+	Balance         decimal.Decimal `json:"balance"`
+}
+
+// storageReader reads values the way vm/embedded.BaseContract stores them
+type storageReader struct {
+	appState *appstate.AppState
+	contract common.Address
+}
+
+func (s *storageReader) get(key string) []byte {
+	return s.appState.State.GetContractValue(s.contract, []byte(key))
+}
+
+func (s *storageReader) byte(key string) byte {
+	v, _ := helpers.ExtractByte(0, s.get(key))
+	return v
+}
+
+func (s *storageReader) optByte(key string) *byte {
+	data := s.get(key)
+	if len(data) == 0 {
+		return nil
+	}
+	return &data[0]
+}
+
+func (s *storageReader) uint64(key string) uint64 {
+	v, _ := helpers.ExtractUInt64(0, s.get(key))
+	return v
+}
+
+func (s *storageReader) uint16(key string) uint16 {
+	v, _ := helpers.ExtractUInt16(0, s.get(key))
+	return v
+}
+
+func (s *storageReader) dna(key string) decimal.Decimal {
+	return blockchain.ConvertToFloat(new(big.Int).SetBytes(s.get(key)))
+}
+
+func (s *storageReader) optDna(key string) *decimal.Decimal {
+	data := s.get(key)
+	if data == nil {
+		return nil
+	}
+	v := blockchain.ConvertToFloat(new(big.Int).SetBytes(data))
+	return &v
+}
+
+func (s *storageReader) address(key string) common.Address {
+	return common.BytesToAddress(s.get(key))
+}
+
+func (s *storageReader) optAddress(key string) *common.Address {
+	data := s.get(key)
+	if data == nil {
+		return nil
+	}
+	addr := common.BytesToAddress(data)
+	return &addr
+}
+
+func (s *storageReader) balance() decimal.Decimal {
+	return blockchain.ConvertToFloat(s.appState.State.GetBalance(s.contract))
+}
+
+func (api *PredefinedApi) storage(contract common.Address, contractType embedded.EmbeddedContractType) (*storageReader, error) {
+	appState := api.contractApi.baseApi.getReadonlyAppState()
+	codeHash := appState.State.GetCodeHash(contract)
+	if codeHash == nil || *codeHash != contractType {
+		return nil, errors.Errorf("%v is not a contract of the requested type", contract.Hex())
+	}
+	return &storageReader{appState: appState, contract: contract}, nil
+}
+
+func (api *PredefinedApi) TimeLockState(contract common.Address) (*TimeLockState, error) {
+	s, err := api.storage(contract, embedded.TimeLockContract)
+	if err != nil {
+		return nil, err
+	}
+	return &TimeLockState{
+		Owner:     s.address("owner"),
+		Timestamp: s.uint64("timestamp"),
+		Balance:   s.balance(),
+	}, nil
+}
+
+func (api *PredefinedApi) MultisigState(contract common.Address) (*MultisigState, error) {
+	s, err := api.storage(contract, embedded.MultisigContract)
+	if err != nil {
+		return nil, err
+	}
+	result := &MultisigState{
+		Owner:    s.address("owner"),
+		MaxVotes: s.byte("maxVotes"),
+		MinVotes: s.byte("minVotes"),
+		State:    s.byte("state"),
+		Count:    s.byte("count"),
+		Balance:  s.balance(),
+	}
+	// participants are stored in the "addr" map with the voted destination as a value
+	prefix := []byte("addr")
+	s.appState.State.IterateContractStore(contract, prefix, maxKeyWithPrefix(prefix), func(key []byte, value []byte) bool {
+		if !bytes.HasPrefix(key, prefix) || len(key) != len(prefix)+common.AddressLength {
+			return false
+		}
+		addr := common.BytesToAddress(key[len(prefix):])
+		amount := s.appState.State.GetContractValue(contract, append([]byte("amount"), addr.Bytes()...))
+		result.Votes = append(result.Votes, &MultisigVote{
+			Address: addr,
+			Dest:    common.BytesToAddress(value),
+			Amount:  blockchain.ConvertToFloat(new(big.Int).SetBytes(amount)),
+		})
+		return false
+	})
+	return result, nil
+}
+
+func (api *PredefinedApi) OracleVotingState(contract common.Address) (*OracleVotingState, error) {
+	s, err := api.storage(contract, embedded.OracleVotingContract)
+	if err != nil {
+		return nil, err
+	}
+	return &OracleVotingState{
+		Owner:                s.address("owner"),
+		State:                s.byte("state"),
+		Fact:                 s.get("fact"),
+		StartTime:            s.uint64("startTime"),
+		StartBlock:           s.uint64("startBlock"),
+		Epoch:                s.uint16("epoch"),
+		VotingDuration:       s.uint64("votingDuration"),
+		PublicVotingDuration: s.uint64("publicVotingDuration"),
+		WinnerThreshold:      s.byte("winnerThreshold"),
+		Quorum:               s.byte("quorum"),
+		CommitteeSize:        s.uint64("committeeSize"),
+		NetworkSize:          s.uint64("network"),
+		OwnerFee:             s.byte("ownerFee"),
+		VotingMinPayment:     s.optDna("votingMinPayment"),
+		OwnerDeposit:         s.optDna("ownerDeposit"),
+		OracleRewardFund:     s.optDna("oracleRewardFund"),
+		RefundRecipient:      s.optAddress("refundRecipient"),
+		VotedCount:           s.uint64("votedCount"),
+		Result:               s.optByte("result"),
+		Balance:              s.balance(),
+		Stake:                blockchain.ConvertToFloat(s.appState.State.GetContractStake(contract)),
+	}, nil
+}
+
+func (api *PredefinedApi) OracleLockState(contract common.Address) (*OracleLockState, error) {
+	s, err := api.storage(contract, embedded.OracleLockContract)
+	if err != nil {
+		return nil, err
+	}
+	result := &OracleLockState{
+		Owner:                  s.address("owner"),
+		OracleVoting:           s.address("oracleVotingAddr"),
+		Value:                  s.byte("value"),
+		SuccessAddr:            s.address("successAddr"),
+		FailAddr:               s.address("failAddr"),
+		IsOracleVotingFinished: s.byte("isOracleVotingFinished") == 1,
+		Balance:                s.balance(),
+	}
+	if s.byte("hasVotedValue") == 1 {
+		result.Voted = s.optByte("voted")
+	}
+	return result, nil
+}
+
+func (api *PredefinedApi) RefundableOracleLockState(contract common.Address) (*RefundableOracleLockState, error) {
+	s, err := api.storage(contract, embedded.RefundableOracleLockContract)
+	if err != nil {
+		return nil, err
+	}
+	return &RefundableOracleLockState{
+		Owner:           s.address("owner"),
+		OracleVoting:    s.address("oracleVoting"),
+		Value:           s.byte("value"),
+		SuccessAddr:     s.optAddress("successAddr"),
+		FailAddr:        s.optAddress("failAddr"),
+		RefundDelay:     s.uint64("refundDelay"),
+		RefundBlock:     s.uint64("refundBlock"),
+		DepositDeadline: s.uint64("depositDeadline"),
+		OracleVotingFee: s.uint64("factEvidenceFee"),
+		State:           s.byte("state"),
+		Sum:             s.dna("sum"),
+		Balance:         s.balance(),
+	}, nil
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-go/blockchain/fee"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/blockchain/validation"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/vm"
+	"math/big"
+)
+
+// applyTxOnState mirrors the way the blockchain applies a transaction to the state: the pay amount, the vm execution,
+// the fee and the nonce. Only transfers and contract transactions change balances, other types are charged the fee.
+// If unsigned is true the tx is executed on behalf of from without a signature. The contract output is returned
+// along with the receipt, the vm execution time is recorded under kind. The vm runs with the gas limit of mined
+// transactions.
+func applyTxOnState(bc *chain.MemBlockchain, appState *appstate.AppState, head *types.Header, tx *types.Transaction,
+	from common.Address, unsigned bool, kind string) (*types.TxReceipt, []string, error) {
+
+	feePerGas := appState.State.FeePerGas()
+	amount := tx.AmountOrZero()
+	receipt := &types.TxReceipt{Success: true, TxHash: tx.Hash(), From: from, GasCost: big.NewInt(0)}
+	var output []string
+
+	switch tx.Type {
+	case types.SendTx:
+		if appState.State.GetBalance(from).Cmp(amount) < 0 {
+			return nil, nil, validation.InsufficientFunds
+		}
+		appState.State.SubBalance(from, amount)
+		appState.State.AddBalance(*tx.To, amount)
+	case types.DeployContractTx, types.CallContractTx, types.TerminateContractTx:
+		var sender *common.Address
+		if unsigned {
+			sender = &from
+		}
+		vm := vm.NewVmImpl(appState, bc, head, nil, bc.Config())
+		shouldAddPayAmount := amount.Sign() > 0 && (tx.Type == types.CallContractTx || vm.IsWasm(tx))
+		contractAddr := vm.ContractAddr(tx, &from)
+		if shouldAddPayAmount {
+			appState.State.SubBalance(from, amount)
+			appState.State.AddBalance(contractAddr, amount)
+		}
+		receipt, output = runVm(bc, vm, appState, tx, sender, gasLimit(bc, appState, tx), kind)
+		if !receipt.Success && shouldAddPayAmount {
+			appState.State.AddBalance(from, amount)
+			appState.State.SubBalance(contractAddr, amount)
+		}
+		if receipt.Success && !shouldAddPayAmount && (tx.Type != types.TerminateContractTx || bc.Config().Consensus.EnableUpgrade11) {
+			appState.State.SubBalance(from, amount)
+		}
+		receipt.GasCost = bc.GetGasCost(appState, receipt.GasUsed)
+	}
+
+	totalFee := new(big.Int).Add(fee.CalculateFee(1, feePerGas, tx), receipt.GasCost)
+	if balance := appState.State.GetBalance(from); balance.Cmp(totalFee) < 0 {
+		totalFee = balance
+	}
+	appState.State.SubBalance(from, totalFee)
+	appState.State.SetNonce(from, tx.AccountNonce)
+	appState.State.SetEpoch(from, tx.Epoch)
+	return receipt, output, nil
+}
+
+// gasLimit is the gas the chain runs the tx with: the max fee left after the tx fee paid by the gas cost
+func gasLimit(bc *chain.MemBlockchain, appState *appstate.AppState, tx *types.Transaction) int64 {
+	feePerGas := appState.State.FeePerGas()
+	oneGasCost := bc.GetGasCost(appState, 1)
+	if oneGasCost.Sign() == 0 {
+		return 0
+	}
+	left := new(big.Int).Sub(tx.MaxFeeOrZero(), fee.CalculateFee(appState.ValidatorsCache.NetworkSize(), feePerGas, tx))
+	if left.Sign() < 0 {
+		// the chain rejects such transactions, the vm treats negative limits as unlimited
+		return 0
+	}
+	return new(big.Int).Quo(left, oneGasCost).Int64()
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// StateOverrides describes temporary modifications of the state an estimate or a readonly call runs against,
+// the modifications are never committed
+type StateOverrides map[common.Address]*AccountOverride
+
+type AccountOverride struct {
+	Balance *decimal.Decimal     `json:"balance"`
+	State   *state.IdentityState `json:"state"`
+	Code    hexutil.Bytes        `json:"code"`
+	Storage []*StorageOverride   `json:"storage"`
+}
+
+type StorageOverride struct {
+	Key    string `json:"key"`
+	Format string `json:"format"`
+	Value  string `json:"value"`
+	Remove bool   `json:"remove"`
+}
+
+func (o StateOverrides) Apply(appState *appstate.AppState) error {
+	for addr, account := range o {
+		if account == nil {
+			continue
+		}
+		if account.Balance != nil {
+			appState.State.SetBalance(addr, blockchain.ConvertToInt(*account.Balance))
+		}
+		if account.State != nil {
+			appState.State.SetState(addr, *account.State)
+		}
+		if len(account.Code) > 0 {
+			appState.State.DeployWasmContract(addr, account.Code)
+		}
+		for _, s := range account.Storage {
+			if s.Remove {
+				appState.State.RemoveContractValue(addr, []byte(s.Key))
+				continue
+			}
+			arg := DynamicArg{Value: s.Value, Format: s.Format}
+			data, err := arg.ToBytes()
+			if err != nil {
+				return errors.Wrapf(err, "storage override of %v, key \"%v\"", addr.Hex(), s.Key)
+			}
+			appState.State.SetContractValue(addr, []byte(s.Key), data)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/mempool"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+type TxPoolApi struct {
+	baseApi *BaseApi
+	pool    *mempool.TxPool
+	bc      *chain.MemBlockchain
+}
+
+func NewTxPoolApi(baseApi *BaseApi, chain *chain.MemBlockchain, pool *mempool.TxPool) *TxPoolApi {
+	return &TxPoolApi{
+		baseApi: baseApi,
+		bc:      chain,
+		pool:    pool,
+	}
+}
+
+// TxPoolAccount splits transactions of a sender into pending ones, which can be mined in the next block,
+// and queued ones, which wait for a missing nonce or the next epoch
+type TxPoolAccount struct {
+	Nonce   uint32         `json:"nonce"`
+	Pending []*Transaction `json:"pending"`
+	Queued  []*Transaction `json:"queued"`
+}
+
+func (api *TxPoolApi) Content() map[common.Address]*TxPoolAccount {
+	appState := api.baseApi.getReadonlyAppState()
+	globalEpoch := appState.State.Epoch()
+	bySender := make(map[common.Address][]*types.Transaction)
+	for _, tx := range api.pool.GetPendingTransaction(true, true, common.MultiShard, false) {
+		sender, _ := types.Sender(tx)
+		bySender[sender] = append(bySender[sender], tx)
+	}
+	result := make(map[common.Address]*TxPoolAccount, len(bySender))
+	for sender, txs := range bySender {
+		sort.Slice(txs, func(i, j int) bool {
+			if txs[i].Epoch != txs[j].Epoch {
+				return txs[i].Epoch < txs[j].Epoch
+			}
+			return txs[i].AccountNonce < txs[j].AccountNonce
+		})
+		nonce := appState.State.GetNonce(sender)
+		if appState.State.GetEpoch(sender) < globalEpoch {
+			nonce = 0
+		}
+		account := &TxPoolAccount{Nonce: nonce}
+		next := nonce + 1
+		for _, tx := range txs {
+			if tx.Epoch == globalEpoch && tx.AccountNonce == next {
+				account.Pending = append(account.Pending, convertTransaction(tx))
+				next++
+			} else {
+				account.Queued = append(account.Queued, convertTransaction(tx))
+			}
+		}
+		result[sender] = account
+	}
+	return result
+}
+
+// Drop removes the transaction from the mempool. Transactions of the sender with higher nonces stay in the mempool,
+// they are not mined until the nonce gap is filled and txpool_content reports them as queued.
+func (api *TxPoolApi) Drop(hash common.Hash) error {
+	tx := api.pool.GetTx(hash)
+	if tx == nil {
+		return errors.Errorf("tx %v is not in the mempool", hash.Hex())
+	}
+	return api.remove([]*types.Transaction{tx})
+}
+
+// Clear removes all transactions from the mempool and returns their number
+func (api *TxPoolApi) Clear() (int, error) {
+	txs := api.pool.GetPendingTransaction(true, true, common.MultiShard, false)
+	return len(txs), api.remove(txs)
+}
+
+func (api *TxPoolApi) remove(txs []*types.Transaction) error {
+	height := api.bc.Head.Height()
+	for _, tx := range txs {
+		api.pool.Remove(tx)
+		api.bc.TxJournal().Drop(tx, height)
+	}
+	return api.bc.ResetNonceCache()
+}
+
+// Removed returns transactions refused by the mempool, evicted from it or dropped, the newest come first
+func (api *TxPoolApi) Removed(limit int) []*chain.RemovedTx {
+	return api.bc.TxJournal().Records(limit)
+}
+
+// RemovalReason returns the latest record of the transaction, nil if it was never removed
+func (api *TxPoolApi) RemovalReason(hash common.Hash) *chain.RemovedTx {
+	return api.bc.TxJournal().Find(hash)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-contract-runner/coverage"
+	"github.com/idena-network/idena-contract-runner/debuglog"
+	"github.com/idena-network/idena-contract-runner/metrics"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/vm"
+	"time"
+)
+
+// runVm executes the tx on the state of the vm with the gas limit, -1 is unlimited, recording the execution time under
+// the kind, the coverage and capturing the contract output. Replays run mined transactions again and are not counted
+// in the coverage.
+func runVm(bc *chain.MemBlockchain, vm vm.VM, appState *appstate.AppState, tx *types.Transaction, from *common.Address, gasLimit int64, kind string) (*types.TxReceipt, []string) {
+	capture := debuglog.Start()
+	start := time.Now()
+	receipt := vm.Run(tx, from, gasLimit)
+	bc.Metrics().ObserveVm(kind, time.Since(start))
+	output := capture.Stop()
+	debuglog.Log(receipt.ContractAddress, receipt.Method, output)
+	if kind != metrics.VmReplay {
+		bc.Coverage().RecordReceipt(receipt, executedCode(bc, appState))
+	}
+	return receipt, output
+}
+
+// readVm reads the contract data on the state of the vm recording the execution time, the coverage and capturing the
+// contract output
+func readVm(bc *chain.MemBlockchain, vm vm.VM, appState *appstate.AppState, contract common.Address, method string, args ...[]byte) ([]byte, []string, error) {
+	capture := debuglog.Start()
+	start := time.Now()
+	data, err := vm.Read(contract, method, args...)
+	bc.Metrics().ObserveVm(metrics.VmRead, time.Since(start))
+	output := capture.Stop()
+	debuglog.Log(contract, method, output)
+	bc.Coverage().Hit(contract, method, executedCode(bc, appState))
+	return data, output, err
+}
+
+// executedCode looks contracts up in the state of the execution, which has contracts deployed by it, and in the head
+// state for contracts terminated by the execution
+func executedCode(bc *chain.MemBlockchain, appState *appstate.AppState) coverage.CodeProvider {
+	return func(contract common.Address) []byte {
+		if code := appState.State.GetContractCode(contract); len(code) > 0 {
+			return code
+		}
+		head, err := bc.ReadonlyAppState()
+		if err != nil {
+			return nil
+		}
+		return head.State.GetContractCode(contract)
+	}
+}
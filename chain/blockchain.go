@@ -2,6 +2,8 @@ package chain
 
 import (
 	"crypto/ecdsa"
+	"github.com/idena-network/idena-contract-runner/coverage"
+	"github.com/idena-network/idena-contract-runner/metrics"
 	"github.com/idena-network/idena-go/blockchain"
 	"github.com/idena-network/idena-go/blockchain/types"
 	"github.com/idena-network/idena-go/blockchain/validation"
@@ -13,26 +15,52 @@ import (
 	"github.com/idena-network/idena-go/core/state"
 	"github.com/idena-network/idena-go/core/upgrade"
 	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/events"
 	"github.com/idena-network/idena-go/ipfs"
 	"github.com/idena-network/idena-go/keystore"
 	"github.com/idena-network/idena-go/secstore"
 	"github.com/idena-network/idena-go/stats/collector"
 	"github.com/idena-network/idena-go/subscriptions"
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	db "github.com/tendermint/tm-db"
 	"log"
 	"math/big"
 	"os"
+	"sync"
 )
 
 type MemBlockchain struct {
 	*blockchain.Blockchain
+	db       db.DB
 	txpool   *mempool.TxPool
 	appstate *appstate.AppState
 	keyStore *keystore.KeyStore
 	secStore *secstore.SecStore
+	bus      eventbus.Bus
+	txIndex  *AddressTxIndex
+	journal  *TxJournal
+	// statsCollector receives stats of applied blocks
+	statsCollector collector.StatsCollector
+	// metrics record vm executions of api calls, nil if the chain runs without metrics
+	metrics *metrics.Metrics
+	// coverage records contract executions of mined blocks and api calls, nil if coverage is disabled
+	coverage     *coverage.Recorder
+	debugOutputs *DebugOutputs
+
+	// keys sign transactions of accounts created without the keystore
+	keys     map[common.Address]*ecdsa.PrivateKey
+	keysLock sync.RWMutex
 
 	setDataMiddlewareValues map[common.Address]map[string][]byte
+
+	// stateUpdates are queued by api calls and applied to the next generated block, blockUpdates are the ones taken
+	// by the generated block, the lock guards the queue and the time shift
+	lock         sync.Mutex
+	stateUpdates []stateUpdate
+	blockUpdates []stateUpdate
+	// timeShift is added to the time of the next generated block
+	timeShift int64
 }
 
 func NewMemBlockchain(godKey *ecdsa.PrivateKey) *MemBlockchain {
@@ -93,9 +121,35 @@ func NewMemBlockchain(godKey *ecdsa.PrivateKey) *MemBlockchain {
 	chain.InitializeChain()
 	appState.Initialize(chain.Head.Height())
 
-	result := &MemBlockchain{chain, txPool, appState, keyStore, secStore, map[common.Address]map[string][]byte{}}
+	result := &MemBlockchain{
+		Blockchain:              chain,
+		db:                      db,
+		txpool:                  txPool,
+		appstate:                appState,
+		keyStore:                keyStore,
+		secStore:                secStore,
+		bus:                     bus,
+		txIndex:                 newAddressTxIndex(),
+		journal:                 newTxJournal(txPool),
+		statsCollector:          collector.NewStatsCollector(),
+		debugOutputs:            newDebugOutputs(),
+		keys:                    map[common.Address]*ecdsa.PrivateKey{},
+		setDataMiddlewareValues: map[common.Address]map[string][]byte{},
+	}
+	bus.Subscribe(events.AddBlockEventID, func(e eventbus.Event) {
+		block := e.(*events.NewBlockEvent).Block
+		result.txIndex.add(block, result.GetReceipt)
+		result.recordCoverage(block)
+		if appState, err := result.ReadonlyAppState(); err == nil {
+			result.journal.onBlock(block, appState)
+		}
+	})
+	bus.Subscribe(events.NewTxEventID, func(e eventbus.Event) {
+		result.journal.track(e.(*events.NewTxEvent).Tx)
+	})
 	txPool.Initialize(chain.Head, secStore.GetAddress(), false)
 	result.UseMiddleware(result.setDataMiddleware)
+	result.UseMiddleware(result.stateUpdatesMiddleware)
 	return result
 }
 
@@ -103,33 +157,182 @@ func (b *MemBlockchain) KeyStore() *keystore.KeyStore {
 	return b.keyStore
 }
 
+// AddKey makes the chain sign transactions of the key address with the key, keys are kept unencrypted in memory like
+// the god key in the secstore, the keystore encrypts keys with the standard scrypt parameters which is slow
+func (b *MemBlockchain) AddKey(key *ecdsa.PrivateKey) common.Address {
+	b.keysLock.Lock()
+	defer b.keysLock.Unlock()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	b.keys[addr] = key
+	return addr
+}
+
+// Key returns the key added for the address, nil if there is no such key
+func (b *MemBlockchain) Key(addr common.Address) *ecdsa.PrivateKey {
+	b.keysLock.RLock()
+	defer b.keysLock.RUnlock()
+	return b.keys[addr]
+}
+
 func (b *MemBlockchain) SecStore() *secstore.SecStore {
 	return b.secStore
 }
 
+func (b *MemBlockchain) Bus() eventbus.Bus {
+	return b.bus
+}
+
+func (b *MemBlockchain) TxIndex() *AddressTxIndex {
+	return b.txIndex
+}
+
+func (b *MemBlockchain) TxJournal() *TxJournal {
+	return b.journal
+}
+
+// SetStatsCollector sets the collector of blocks added by GenerateBlocks
+func (b *MemBlockchain) SetStatsCollector(statsCollector collector.StatsCollector) {
+	b.statsCollector = statsCollector
+}
+
+// SetMetrics sets the metrics of vm executions outside of blocks
+func (b *MemBlockchain) SetMetrics(metrics *metrics.Metrics) {
+	b.metrics = metrics
+}
+
+func (b *MemBlockchain) Metrics() *metrics.Metrics {
+	return b.metrics
+}
+
+// SetCoverage sets the coverage recorder of contract executions in mined blocks and api calls
+func (b *MemBlockchain) SetCoverage(recorder *coverage.Recorder) {
+	b.coverage = recorder
+}
+
+func (b *MemBlockchain) Coverage() *coverage.Recorder {
+	return b.coverage
+}
+
+func (b *MemBlockchain) recordCoverage(block *types.Block) {
+	if b.coverage == nil {
+		return
+	}
+	code := func(contract common.Address) []byte {
+		return b.ContractCodeAt(block.Height(), contract)
+	}
+	for _, tx := range block.Body.Transactions {
+		if receipt := b.GetReceipt(tx.Hash()); receipt != nil {
+			b.coverage.RecordReceipt(receipt, code)
+		}
+	}
+}
+
+// ContractCodeAt returns the code of the contract in the state of the block or, for contracts terminated in the block,
+// in the state of its parent
+func (b *MemBlockchain) ContractCodeAt(height uint64, contract common.Address) []byte {
+	for _, h := range []uint64{height, height - 1} {
+		appState, err := b.ReadonlyAppStateAt(h)
+		if err != nil {
+			continue
+		}
+		if code := appState.State.GetContractCode(contract); len(code) > 0 {
+			return code
+		}
+	}
+	return nil
+}
+
+func (b *MemBlockchain) DebugOutputs() *DebugOutputs {
+	return b.debugOutputs
+}
+
 func (b *MemBlockchain) AppStateForCheck() (*appstate.AppState, error) {
 	return b.appstate.ForCheck(0)
 }
+func (b *MemBlockchain) AppStateForCheckAt(height uint64) (*appstate.AppState, error) {
+	return b.appstate.ForCheck(height)
+}
+
+// ReadonlyAppState returns the state of the head block, the app state caches the readonly state of the last requested
+// height, so the latest state is requested by the height to not get a stale or reverted state
 func (b *MemBlockchain) ReadonlyAppState() (*appstate.AppState, error) {
-	return b.appstate.Readonly(0)
+	return b.appstate.Readonly(b.Head.Height())
+}
+
+func (b *MemBlockchain) ReadonlyAppStateAt(height uint64) (*appstate.AppState, error) {
+	return b.appstate.Readonly(height)
+}
+
+// IterateContractStoreDesc calls f for the store keys of the contract in the committed state of the height from the
+// exclusive upper bound down to minKey until f returns true, the state db iterates contract stores in ascending order only
+func (b *MemBlockchain) IterateContractStoreDesc(height uint64, contract common.Address, minKey, end []byte, f func(key []byte, value []byte) bool) error {
+	prefix, err := state.StateDbKeys.LoadDbPrefix(b.db)
+	if err != nil {
+		return err
+	}
+	tree := state.NewMutableTree(db.NewPrefixDB(b.db, prefix))
+	if _, err := tree.LazyLoad(int64(height)); err != nil {
+		return errors.Wrapf(err, "state of block %v is not available", height)
+	}
+	keyPrefixLen := len(state.StateDbKeys.ContractStoreKey(contract, nil))
+	tree.GetImmutable().IterateRange(state.StateDbKeys.ContractStoreKey(contract, minKey), state.StateDbKeys.ContractStoreKey(contract, end), false,
+		func(key []byte, value []byte) bool {
+			return f(key[keyPrefixLen:], value)
+		})
+	return nil
 }
 
 func (b *MemBlockchain) TxPool() *mempool.TxPool {
 	return b.txpool
 }
 
+// ResetNonceCache rebuilds mempool nonces from the head state and the transactions left in the mempool, the cache
+// keeps counting removed transactions otherwise
+func (b *MemBlockchain) ResetNonceCache() error {
+	appState, err := b.AppStateForCheck()
+	if err != nil {
+		return err
+	}
+	appState.NonceCache.Lock()
+	defer appState.NonceCache.UnLock()
+	appState.NonceCache.Clear()
+	if err := appState.NonceCache.ReloadFallback(appState.State); err != nil {
+		return err
+	}
+	for _, tx := range b.txpool.GetPendingTransaction(true, true, common.MultiShard, false) {
+		sender, _ := types.Sender(tx)
+		appState.NonceCache.UnsafeSetNonce(sender, tx.Epoch, tx.AccountNonce)
+	}
+	return nil
+}
+
 func (b *MemBlockchain) GenerateBlocks(count int) {
 	for i := 0; i < count; i++ {
-		block := b.ProposeBlock([]byte{})
-		block.Block.Header.ProposedHeader.Time = b.Head.Time() + 20
-		err := b.AddBlock(block.Block, nil, collector.NewStatsCollector())
+		b.lock.Lock()
+		// the middleware applies the updates both to the proposed and to the inserted block, updates queued meanwhile
+		// wait for the next block
+		b.blockUpdates, b.stateUpdates = b.stateUpdates, nil
+		timeShift := b.timeShift
+		b.timeShift = 0
+		b.lock.Unlock()
+
+		block := b.proposeBlockAt(b.Head.Time() + 20 + timeShift)
+		err := b.AddBlock(block, nil, &debugCollector{StatsCollector: b.statsCollector, outputs: b.debugOutputs})
 		if err != nil {
 			panic(err)
 		}
-		b.addCert(block.Block)
+		b.blockUpdates = nil
+		b.addCert(block)
 	}
 }
 
+// AdvanceTime moves the time of the next generated block forward
+func (b *MemBlockchain) AdvanceTime(seconds int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.timeShift += seconds
+}
+
 func (b *MemBlockchain) addCert(block *types.Block) {
 	vote := &types.Vote{
 		Header: &types.VoteHeader{
@@ -146,12 +349,43 @@ func (b *MemBlockchain) addCert(block *types.Block) {
 	b.WriteCertificate(block.Header.Hash(), cert.Compress(), true)
 }
 
+// SetIdentity sets the identity state of the address in the next block, changes of the head state are lost when a
+// block is inserted
 func (b *MemBlockchain) SetIdentity(addr common.Address, status state.IdentityState) {
-	b.appstate.State.SetState(addr, status)
+	b.queueUpdate(func(appState *appstate.AppState) {
+		appState.State.SetState(addr, status)
+	})
 }
 
+// AddBalance credits the address in the next block
 func (b *MemBlockchain) AddBalance(addr common.Address, amount decimal.Decimal) {
-	b.appstate.State.AddBalance(addr, blockchain.ConvertToInt(amount))
+	value := blockchain.ConvertToInt(amount)
+	b.queueUpdate(func(appState *appstate.AppState) {
+		appState.State.AddBalance(addr, value)
+	})
+}
+
+// SetBalance sets the balance of the address in the next block
+func (b *MemBlockchain) SetBalance(addr common.Address, amount decimal.Decimal) {
+	value := blockchain.ConvertToInt(amount)
+	b.queueUpdate(func(appState *appstate.AppState) {
+		appState.State.SetBalance(addr, value)
+	})
+}
+
+// stateUpdate changes the state of the block it is applied to
+type stateUpdate func(appState *appstate.AppState)
+
+func (b *MemBlockchain) queueUpdate(update stateUpdate) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.stateUpdates = append(b.stateUpdates, update)
+}
+
+func (b *MemBlockchain) stateUpdatesMiddleware(block *types.Block, appState *appstate.AppState) {
+	for _, update := range b.blockUpdates {
+		update(appState)
+	}
 }
 
 func (b *MemBlockchain) SetContractData(addr common.Address, key string, data []byte) {
@@ -0,0 +1,105 @@
+package chain
+
+import (
+	"github.com/idena-network/idena-contract-runner/debuglog"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/stats/collector"
+	"sync"
+)
+
+const maxDebugOutputs = 1000
+
+// DebugOutputs keeps the contract output of the latest mined transactions
+type DebugOutputs struct {
+	lock    sync.RWMutex
+	outputs map[common.Hash][]string
+	hashes  []common.Hash
+}
+
+func newDebugOutputs() *DebugOutputs {
+	return &DebugOutputs{outputs: map[common.Hash][]string{}}
+}
+
+func (o *DebugOutputs) add(hash common.Hash, lines []string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if _, ok := o.outputs[hash]; !ok {
+		o.hashes = append(o.hashes, hash)
+	}
+	o.outputs[hash] = lines
+	if len(o.hashes) > maxDebugOutputs {
+		delete(o.outputs, o.hashes[0])
+		o.hashes = o.hashes[1:]
+	}
+}
+
+// Get returns the output of the mined transaction, nil if the contract printed nothing or the output is evicted
+func (o *DebugOutputs) Get(hash common.Hash) []string {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	return o.outputs[hash]
+}
+
+// debugCollector captures the contract output while the vm runs a contract tx. The chain has no hook around vm.Run,
+// so the capture starts with the tx applying, pauses for the balance update of the pay amount and stops at the first
+// balance update after the run, the chain only checks the tx and charges the fee before the run.
+type debugCollector struct {
+	collector.StatsCollector
+	outputs *DebugOutputs
+	capture *debuglog.Capture
+	// running is set from the start of a contract tx applying until its receipt
+	running bool
+	lines   []string
+}
+
+func (c *debugCollector) BeginApplyingTx(tx *types.Transaction, appState *appstate.AppState) {
+	c.StatsCollector.BeginApplyingTx(tx, appState)
+	switch tx.Type {
+	case types.DeployContractTx, types.CallContractTx, types.TerminateContractTx:
+		c.running = true
+		c.lines = nil
+		c.capture = debuglog.Start()
+	}
+}
+
+func (c *debugCollector) BeginTxBalanceUpdate(tx *types.Transaction, appState *appstate.AppState) {
+	c.lines = append(c.lines, c.stop()...)
+	c.StatsCollector.BeginTxBalanceUpdate(tx, appState)
+}
+
+func (c *debugCollector) CompleteBalanceUpdate(appState *appstate.AppState) {
+	c.StatsCollector.CompleteBalanceUpdate(appState)
+	// the balance update before the receipt pays the amount to the contract, the vm runs right after it
+	if c.running && c.capture == nil {
+		c.capture = debuglog.Start()
+	}
+}
+
+func (c *debugCollector) AddTxReceipt(txReceipt *types.TxReceipt, appState *appstate.AppState) {
+	lines := append(c.lines, c.stop()...)
+	c.running = false
+	c.lines = nil
+	if len(lines) > 0 {
+		debuglog.Log(txReceipt.ContractAddress, txReceipt.Method, lines)
+		c.outputs.add(txReceipt.TxHash, lines)
+	}
+	c.StatsCollector.AddTxReceipt(txReceipt, appState)
+}
+
+func (c *debugCollector) CompleteApplyingTx(appState *appstate.AppState) {
+	c.stop()
+	c.running = false
+	c.lines = nil
+	c.StatsCollector.CompleteApplyingTx(appState)
+}
+
+func (c *debugCollector) stop() []string {
+	if c.capture == nil {
+		return nil
+	}
+	lines := c.capture.Stop()
+	c.capture = nil
+	return lines
+}
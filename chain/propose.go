@@ -0,0 +1,90 @@
+package chain
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/stats/collector"
+)
+
+// receiptCollector keeps the receipts of contract transactions the chain applies
+type receiptCollector struct {
+	collector.StatsCollector
+	receipts types.TxReceipts
+}
+
+func (c *receiptCollector) AddTxReceipt(receipt *types.TxReceipt, appState *appstate.AppState) {
+	c.receipts = append(c.receipts, receipt)
+}
+
+// proposeBlockAt proposes a block with the time. The chain proposes blocks with the local time and runs the
+// transactions at it, so the block is sealed again when the time differs: the transactions are run at the time to get
+// the receipts, the tx bloom and the receipts cid, then the block is applied to get the state roots. Validation errors
+// of both runs are ignored, the insertion validates the block.
+func (b *MemBlockchain) proposeBlockAt(time int64) *types.Block {
+	block := b.ProposeBlock([]byte{}).Block
+	header := block.Header.ProposedHeader
+	if header.Time == time {
+		return block
+	}
+	header.Time = time
+
+	receipts := &receiptCollector{StatsCollector: collector.NewStatsCollector()}
+	if checkState, err := b.appstate.ForCheck(b.Head.Height()); err == nil {
+		// the run stops at the tx bloom or later, the receipts are collected before
+		b.ValidateBlock(block, checkState, receipts)
+	}
+	header.TxBloom = txBloom(block, receipts.receipts)
+	header.TxReceiptsCid = receiptsCid(receipts.receipts)
+
+	if checkState, err := b.appstate.ForCheck(b.Head.Height()); err == nil {
+		// the run stops at the roots at the latest, the check state is applied at that point
+		b.ValidateBlock(block, checkState, collector.NewStatsCollector())
+		header.Root = checkState.State.Root()
+		header.IdentityRoot = checkState.IdentityState.Root()
+	}
+	return block
+}
+
+// txBloom calculates the tx bloom of the block like the chain does
+func txBloom(block *types.Block, receipts types.TxReceipts) []byte {
+	if len(block.Body.Transactions) == 0 {
+		return []byte{}
+	}
+	values := make(map[string]struct{})
+	for _, tx := range block.Body.Transactions {
+		sender, _ := types.Sender(tx)
+		values[string(sender.Bytes())] = struct{}{}
+		if tx.To != nil {
+			values[string(tx.To.Bytes())] = struct{}{}
+		}
+	}
+	for _, r := range receipts {
+		for _, e := range r.Events {
+			contract := r.ContractAddress
+			if !e.Contract.IsEmpty() {
+				contract = e.Contract
+			}
+			values[string(append(contract.Bytes(), []byte(e.EventName)...))] = struct{}{}
+		}
+	}
+	bloom := common.NewSerializableBF(len(values))
+	for value := range values {
+		bloom.Add([]byte(value))
+	}
+	data, _ := bloom.Serialize()
+	return data
+}
+
+func receiptsCid(receipts types.TxReceipts) []byte {
+	if receipts == nil {
+		return nil
+	}
+	data, _ := receipts.ToBytes()
+	cid, _ := ipfs.NewMemoryIpfsProxy().Cid(data)
+	if cid == ipfs.EmptyCid {
+		return nil
+	}
+	return cid.Bytes()
+}
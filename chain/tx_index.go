@@ -0,0 +1,81 @@
+package chain
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"sync"
+)
+
+type AddressTx struct {
+	Hash   common.Hash
+	Height uint64
+	Index  uint32
+}
+
+// AddressTxIndex keeps mined transactions of senders, recipients and deployed contracts in the order of mining
+type AddressTxIndex struct {
+	lock       sync.RWMutex
+	txs        map[common.Address][]*AddressTx
+	lastHeight uint64
+}
+
+func newAddressTxIndex() *AddressTxIndex {
+	return &AddressTxIndex{txs: map[common.Address][]*AddressTx{}}
+}
+
+// add indexes the block, blocks above it are left from a chain reset and are dropped first
+func (i *AddressTxIndex) add(block *types.Block, receipt func(hash common.Hash) *types.TxReceipt) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if block.Height() <= i.lastHeight {
+		i.truncate(block.Height())
+	}
+	i.lastHeight = block.Height()
+	if block.Body == nil {
+		return
+	}
+	for index, tx := range block.Body.Transactions {
+		entry := &AddressTx{Hash: tx.Hash(), Height: block.Height(), Index: uint32(index)}
+		addresses := make(map[common.Address]struct{})
+		if sender, err := types.Sender(tx); err == nil {
+			addresses[sender] = struct{}{}
+		}
+		if tx.To != nil {
+			addresses[*tx.To] = struct{}{}
+		}
+		if tx.Type == types.DeployContractTx {
+			if r := receipt(entry.Hash); r != nil {
+				addresses[r.ContractAddress] = struct{}{}
+			}
+		}
+		for addr := range addresses {
+			i.txs[addr] = append(i.txs[addr], entry)
+		}
+	}
+}
+
+func (i *AddressTxIndex) truncate(height uint64) {
+	for addr, txs := range i.txs {
+		n := len(txs)
+		for n > 0 && txs[n-1].Height >= height {
+			n--
+		}
+		if n == 0 {
+			delete(i.txs, addr)
+		} else {
+			i.txs[addr] = txs[:n]
+		}
+	}
+}
+
+// Transactions returns transactions of the address mined up to the height in ascending order
+func (i *AddressTxIndex) Transactions(addr common.Address, maxHeight uint64) []*AddressTx {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	txs := i.txs[addr]
+	n := len(txs)
+	for n > 0 && txs[n-1].Height > maxHeight {
+		n--
+	}
+	return append([]*AddressTx{}, txs[:n]...)
+}
@@ -0,0 +1,131 @@
+package chain
+
+import (
+	"github.com/idena-network/idena-go/blockchain/fee"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/blockchain/validation"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/core/mempool"
+	"sync"
+	"time"
+)
+
+const (
+	TxRejected = "rejected"
+	TxEvicted  = "evicted"
+	TxDropped  = "dropped"
+
+	maxJournalRecords = 1000
+)
+
+// RemovedTx is a transaction which was refused by the mempool or left it without being mined
+type RemovedTx struct {
+	Hash   common.Hash    `json:"hash"`
+	From   common.Address `json:"from"`
+	Nonce  uint32         `json:"nonce"`
+	Epoch  uint16         `json:"epoch"`
+	Kind   string         `json:"kind"`
+	Reason string         `json:"reason"`
+	// head height at the moment of removal
+	Height uint64    `json:"height"`
+	Time   time.Time `json:"time"`
+}
+
+// TxJournal keeps the latest removed transactions, evictions are found by comparing the mempool before and after
+// every block
+type TxJournal struct {
+	lock    sync.Mutex
+	pool    *mempool.TxPool
+	tracked map[common.Hash]*types.Transaction
+	records []*RemovedTx
+}
+
+func newTxJournal(pool *mempool.TxPool) *TxJournal {
+	return &TxJournal{pool: pool, tracked: map[common.Hash]*types.Transaction{}}
+}
+
+func (j *TxJournal) track(tx *types.Transaction) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.tracked[tx.Hash()] = tx
+}
+
+func (j *TxJournal) record(tx *types.Transaction, kind string, reason string, height uint64) {
+	sender, _ := types.Sender(tx)
+	j.records = append(j.records, &RemovedTx{
+		Hash:   tx.Hash(),
+		From:   sender,
+		Nonce:  tx.AccountNonce,
+		Epoch:  tx.Epoch,
+		Kind:   kind,
+		Reason: reason,
+		Height: height,
+		Time:   time.Now().UTC(),
+	})
+	if len(j.records) > maxJournalRecords {
+		j.records = j.records[len(j.records)-maxJournalRecords:]
+	}
+}
+
+// Reject records a transaction refused by the mempool
+func (j *TxJournal) Reject(tx *types.Transaction, err error, height uint64) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.record(tx, TxRejected, err.Error(), height)
+}
+
+// Drop records a transaction removed from the mempool on request
+func (j *TxJournal) Drop(tx *types.Transaction, height uint64) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	delete(j.tracked, tx.Hash())
+	j.record(tx, TxDropped, "dropped by request", height)
+}
+
+// onBlock is called after the mempool was reset to the block, tracked transactions which are neither mined nor left
+// in the mempool were evicted, the reason is taken from the validation against the new state
+func (j *TxJournal) onBlock(block *types.Block, appState *appstate.AppState) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if block.Body != nil {
+		for _, tx := range block.Body.Transactions {
+			delete(j.tracked, tx.Hash())
+		}
+	}
+	minFeePerGas := fee.GetFeePerGasForNetwork(appState.ValidatorsCache.NetworkSize())
+	for hash, tx := range j.tracked {
+		if j.pool.GetTx(hash) != nil {
+			continue
+		}
+		delete(j.tracked, hash)
+		reason := "removed after an invalid transaction with a lower nonce"
+		if err := validation.ValidateTx(appState, tx, minFeePerGas, validation.MempoolTx); err != nil {
+			reason = err.Error()
+		}
+		j.record(tx, TxEvicted, reason, block.Height())
+	}
+}
+
+// Records returns removed transactions, the newest come first
+func (j *TxJournal) Records(limit int) []*RemovedTx {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	var result []*RemovedTx
+	for i := len(j.records) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		result = append(result, j.records[i])
+	}
+	return result
+}
+
+// Find returns the latest record of the transaction
+func (j *TxJournal) Find(hash common.Hash) *RemovedTx {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	for i := len(j.records) - 1; i >= 0; i-- {
+		if j.records[i].Hash == hash {
+			return j.records[i]
+		}
+	}
+	return nil
+}
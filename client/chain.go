@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"github.com/idena-network/idena-contract-runner/api"
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const defaultPollInterval = 100 * time.Millisecond
+
+func (c *Client) GenerateBlocks(ctx context.Context, count int) error {
+	return c.Call(ctx, nil, "chain_generateBlocks", count)
+}
+
+func (c *Client) TxReceipt(ctx context.Context, hash common.Hash) (*api.TxReceipt, error) {
+	var receipt *api.TxReceipt
+	err := c.Call(ctx, &receipt, "chain_txReceipt", hash)
+	return receipt, err
+}
+
+// WaitForReceipt polls the receipt of the tx until it is mined, removed from the mempool or ctx is done, blocks are
+// not generated
+func (c *Client) WaitForReceipt(ctx context.Context, hash common.Hash, pollInterval time.Duration) (*api.TxReceipt, error) {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		tx, err := c.GetTransaction(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if tx != nil && tx.Status != api.TxStatusPending {
+			return c.TxReceipt(ctx, hash)
+		}
+		if tx == nil {
+			if removed, err := c.TxPoolRemovalReason(ctx, hash); err != nil {
+				return nil, err
+			} else if removed != nil {
+				return nil, errors.Errorf("tx %v was %v: %v", hash.Hex(), removed.Kind, removed.Reason)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "tx %v is not mined", hash.Hex())
+		case <-ticker.C:
+		}
+	}
+}
+
+// MineReceipt generates a block and returns the receipt of the tx, the tx must be in the mempool
+func (c *Client) MineReceipt(ctx context.Context, hash common.Hash) (*api.TxReceipt, error) {
+	if err := c.GenerateBlocks(ctx, 1); err != nil {
+		return nil, err
+	}
+	tx, err := c.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil || tx.Status == api.TxStatusPending {
+		return nil, errors.Errorf("tx %v is not mined", hash.Hex())
+	}
+	return c.TxReceipt(ctx, hash)
+}
+
+func (c *Client) ResetTo(ctx context.Context, height uint64) error {
+	return c.Call(ctx, nil, "chain_resetTo", height)
+}
+
+// SetIdentity sets the identity state of the address, reads see the state after the next block
+func (c *Client) SetIdentity(ctx context.Context, addr common.Address, status state.IdentityState) error {
+	return c.Call(ctx, nil, "chain_setIdentity", addr, status)
+}
+
+// AddBalance credits the address, reads see the balance after the next block
+func (c *Client) AddBalance(ctx context.Context, addr common.Address, amount decimal.Decimal) error {
+	return c.Call(ctx, nil, "chain_addBalance", addr, amount)
+}
+
+func (c *Client) GetBalance(ctx context.Context, addr common.Address, blockNumber *rpc.BlockNumber) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	err := c.Call(ctx, &balance, "chain_getBalance", addr, blockNumber)
+	return balance, err
+}
+
+func (c *Client) SetContractData(ctx context.Context, addr common.Address, key string, value string, format string) error {
+	return c.Call(ctx, nil, "chain_setContractData", addr, key, value, format)
+}
+
+func (c *Client) God(ctx context.Context) (common.Address, error) {
+	var addr common.Address
+	err := c.Call(ctx, &addr, "chain_god")
+	return addr, err
+}
+
+func (c *Client) Head(ctx context.Context) (*api.Block, error) {
+	var block *api.Block
+	err := c.Call(ctx, &block, "chain_head")
+	return block, err
+}
+
+func (c *Client) GetBlockByHeight(ctx context.Context, height uint64) (*api.Block, error) {
+	var block *api.Block
+	err := c.Call(ctx, &block, "chain_getBlockByHeight", height)
+	return block, err
+}
+
+func (c *Client) GetBlockByHash(ctx context.Context, hash common.Hash) (*api.Block, error) {
+	var block *api.Block
+	err := c.Call(ctx, &block, "chain_getBlockByHash", hash)
+	return block, err
+}
+
+func (c *Client) GetBlocks(ctx context.Context, from, to uint64) ([]*api.Block, error) {
+	var blocks []*api.Block
+	err := c.Call(ctx, &blocks, "chain_getBlocks", from, to)
+	return blocks, err
+}
+
+func (c *Client) GetTransaction(ctx context.Context, hash common.Hash) (*api.Transaction, error) {
+	var tx *api.Transaction
+	err := c.Call(ctx, &tx, "chain_getTransaction", hash)
+	return tx, err
+}
+
+func (c *Client) GetTransactionsByAddress(ctx context.Context, address common.Address, limit int, continuationToken *hexutil.Bytes) (*api.GetTransactionsResponse, error) {
+	var result *api.GetTransactionsResponse
+	err := c.Call(ctx, &result, "chain_getTransactionsByAddress", address, limit, continuationToken)
+	return result, err
+}
+
+func (c *Client) TxPoolContent(ctx context.Context) (map[common.Address]*api.TxPoolAccount, error) {
+	var result map[common.Address]*api.TxPoolAccount
+	err := c.Call(ctx, &result, "txpool_content")
+	return result, err
+}
+
+func (c *Client) TxPoolDrop(ctx context.Context, hash common.Hash) error {
+	return c.Call(ctx, nil, "txpool_drop", hash)
+}
+
+func (c *Client) TxPoolClear(ctx context.Context) (int, error) {
+	var count int
+	err := c.Call(ctx, &count, "txpool_clear")
+	return count, err
+}
+
+func (c *Client) TxPoolRemoved(ctx context.Context, limit int) ([]*chain.RemovedTx, error) {
+	var result []*chain.RemovedTx
+	err := c.Call(ctx, &result, "txpool_removed", limit)
+	return result, err
+}
+
+func (c *Client) TxPoolRemovalReason(ctx context.Context, hash common.Hash) (*chain.RemovedTx, error) {
+	var result *chain.RemovedTx
+	err := c.Call(ctx, &result, "txpool_removalReason", hash)
+	return result, err
+}
+
+func (c *Client) ReplayTransaction(ctx context.Context, hash common.Hash) (*api.ReplayResult, error) {
+	var result *api.ReplayResult
+	err := c.Call(ctx, &result, "debug_replayTransaction", hash)
+	return result, err
+}
+
+// CoverageReport decodes the coverage report into result, coverage must be enabled on the runner
+func (c *Client) CoverageReport(ctx context.Context, format string, result interface{}) error {
+	return c.Call(ctx, result, "coverage_report", format)
+}
+
+func (c *Client) CoverageReset(ctx context.Context) error {
+	return c.Call(ctx, nil, "coverage_reset")
+}
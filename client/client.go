@@ -0,0 +1,143 @@
+// Package client is a typed client of the runner JSON-RPC api, request and response types are shared with the api
+// package.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-contract-runner/api"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+)
+
+const DefaultEndpoint = "http://localhost:3333"
+
+// Error is an error returned by the runner, Data is set for structured errors of rejected transactions and failed
+// contract executions
+type Error struct {
+	Code    int            `json:"code"`
+	Message string         `json:"message"`
+	Data    *api.ErrorData `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("json-rpc error %d", e.Code)
+	}
+	return e.Message
+}
+
+func (e *Error) ErrorCode() int {
+	return e.Code
+}
+
+// ErrorCode returns the code of the runner error or 0 if err is not returned by the runner
+func ErrorCode(err error) int {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code
+	}
+	return 0
+}
+
+// IsErrorCode reports whether err is returned by the runner with the code, e.g. api.ErrCodeOutOfGas
+func IsErrorCode(err error, code int) bool {
+	return ErrorCode(err) == code
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient sets the http client, http.DefaultClient is used by default
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithApiKey sets the key sent with every request
+func WithApiKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+type Client struct {
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+	id         uint64
+}
+
+func New(endpoint string, options ...Option) *Client {
+	c := &Client{
+		endpoint:   endpoint,
+		httpClient: http.DefaultClient,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+type request struct {
+	Version string        `json:"jsonrpc"`
+	Id      uint64        `json:"id"`
+	Key     string        `json:"key,omitempty"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type response struct {
+	Id     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Call invokes the method and decodes its result into result, result may be nil if the result is not needed
+func (c *Client) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(&request{
+		Version: "2.0",
+		Id:      atomic.AddUint64(&c.id, 1),
+		Key:     c.apiKey,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode %v params", method)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "%v failed", method)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %v response", method)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("%v failed: %v %s", method, resp.Status, bytes.TrimSpace(data))
+	}
+	var r response
+	if err := json.Unmarshal(data, &r); err != nil {
+		return errors.Wrapf(err, "failed to decode %v response", method)
+	}
+	if r.Error != nil {
+		return r.Error
+	}
+	if result == nil || len(r.Result) == 0 {
+		return nil
+	}
+	return errors.Wrapf(json.Unmarshal(r.Result, result), "failed to decode %v result", method)
+}
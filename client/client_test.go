@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"github.com/idena-network/idena-contract-runner/api"
+	"github.com/idena-network/idena-contract-runner/harness"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/idena-network/idena-go/rpc"
+	"github.com/idena-network/idena-wasm-binding/tests/testdata"
+	"github.com/shopspring/decimal"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestClient serves the apis of a harness chain over http like the runner does, error responses carry the
+// generic code only
+func newTestClient(t *testing.T) (*Client, *harness.Chain) {
+	c, err := harness.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc := c.Blockchain()
+	baseApi := api.NewBaseApi(bc, bc.KeyStore(), bc.SecStore(), ipfs.NewMemoryIpfsProxy(), bc.TxPool())
+	srv := rpc.NewServer("")
+	services := map[string]interface{}{
+		"contract":   c.ContractApi(),
+		"chain":      c.ChainApi(),
+		"txpool":     c.TxPoolApi(),
+		"debug":      c.DebugApi(),
+		"predefined": c.PredefinedApi(),
+		"dna":        api.NewDnaApi(baseApi, bc, "test"),
+		"bcn":        api.NewBcnApi(baseApi, bc, bc.TxPool()),
+	}
+	for namespace, service := range services {
+		if err := srv.RegisterName(namespace, service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := httptest.NewServer(srv)
+	t.Cleanup(server.Close)
+	return New(server.URL), c
+}
+
+func TestNode(t *testing.T) {
+	client, c := newTestClient(t)
+	ctx := context.Background()
+
+	coinbase, err := client.DnaCoinbaseAddr(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coinbase != c.God() {
+		t.Fatalf("expected coinbase %v, got %v", c.God().Hex(), coinbase.Hex())
+	}
+	version, err := client.DnaVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "test" {
+		t.Fatalf("unexpected version %v", version)
+	}
+	balance, err := client.DnaBalance(ctx, coinbase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !balance.Balance.IsPositive() {
+		t.Fatalf("expected a positive god balance, got %v", balance.Balance)
+	}
+
+	signature, err := client.DnaSign(ctx, "message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := client.DnaSignatureAddress(ctx, api.SignatureAddressArgs{Value: "message", Signature: signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != coinbase {
+		t.Fatalf("expected signer %v, got %v", coinbase.Hex(), signer.Hex())
+	}
+
+	if err := client.GenerateBlocks(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	block, err := client.BcnLastBlock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Height != c.Height() {
+		t.Fatalf("expected last block %v, got %v", c.Height(), block.Height)
+	}
+	syncing, err := client.BcnSyncing(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if syncing.Syncing || syncing.CurrentBlock != c.Height() {
+		t.Fatalf("unexpected syncing state %+v", syncing)
+	}
+	if _, err := client.BcnFeePerGas(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContract(t *testing.T) {
+	client, c := newTestClient(t)
+	ctx := context.Background()
+	// the fee per gas of the genesis state is zero, so transactions get no gas until the first block
+	if err := client.GenerateBlocks(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	code, err := testdata.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt, err := client.DeployAndMine(ctx, api.DeployArgs{
+		From:   c.God(),
+		Code:   code,
+		MaxFee: decimal.NewFromInt(1000),
+		Args:   api.DynamicArgs{{Index: 0, Format: "uint64", Value: "1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Success {
+		t.Fatalf("deploy failed: %v", receipt.Error)
+	}
+	var state string
+	if err := client.ReadData(ctx, receipt.Contract, "STATE", "string", nil, &state); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(state, "result") {
+		t.Fatalf("unexpected contract state %v", state)
+	}
+	tx, err := client.BcnTransaction(ctx, *receipt.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx == nil || tx.BlockHash != receipt.BlockHash {
+		t.Fatalf("unexpected transaction %+v", tx)
+	}
+
+	err = client.ReadData(ctx, receipt.Contract, "missing", "string", nil, &state)
+	if ErrorCode(err) == 0 {
+		t.Fatalf("expected a runner error, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"github.com/idena-network/idena-contract-runner/api"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/rpc"
+)
+
+func (c *Client) Deploy(ctx context.Context, args api.DeployArgs) (common.Hash, error) {
+	var hash common.Hash
+	err := c.Call(ctx, &hash, "contract_deploy", args)
+	return hash, err
+}
+
+func (c *Client) CallContract(ctx context.Context, args api.CallArgs) (common.Hash, error) {
+	var hash common.Hash
+	err := c.Call(ctx, &hash, "contract_call", args)
+	return hash, err
+}
+
+func (c *Client) Terminate(ctx context.Context, args api.TerminateArgs) (common.Hash, error) {
+	var hash common.Hash
+	err := c.Call(ctx, &hash, "contract_terminate", args)
+	return hash, err
+}
+
+func (c *Client) DeployAndMine(ctx context.Context, args api.DeployArgs) (*api.MinedTxReceipt, error) {
+	var receipt *api.MinedTxReceipt
+	err := c.Call(ctx, &receipt, "contract_deployAndMine", args)
+	return receipt, err
+}
+
+func (c *Client) CallAndMine(ctx context.Context, args api.CallArgs) (*api.MinedTxReceipt, error) {
+	var receipt *api.MinedTxReceipt
+	err := c.Call(ctx, &receipt, "contract_callAndMine", args)
+	return receipt, err
+}
+
+func (c *Client) TerminateAndMine(ctx context.Context, args api.TerminateArgs) (*api.MinedTxReceipt, error) {
+	var receipt *api.MinedTxReceipt
+	err := c.Call(ctx, &receipt, "contract_terminateAndMine", args)
+	return receipt, err
+}
+
+func (c *Client) EstimateDeploy(ctx context.Context, args api.DeployArgs, stateOverrides api.StateOverrides) (*api.TxReceipt, error) {
+	var receipt *api.TxReceipt
+	err := c.Call(ctx, &receipt, "contract_estimateDeploy", args, stateOverrides)
+	return receipt, err
+}
+
+func (c *Client) EstimateCall(ctx context.Context, args api.CallArgs, stateOverrides api.StateOverrides) (*api.TxReceipt, error) {
+	var receipt *api.TxReceipt
+	err := c.Call(ctx, &receipt, "contract_estimateCall", args, stateOverrides)
+	return receipt, err
+}
+
+func (c *Client) EstimateTerminate(ctx context.Context, args api.TerminateArgs) (*api.TxReceipt, error) {
+	var receipt *api.TxReceipt
+	err := c.Call(ctx, &receipt, "contract_estimateTerminate", args)
+	return receipt, err
+}
+
+func (c *Client) SimulateBundle(ctx context.Context, args api.SimulateBundleArgs) (*api.SimulateBundleResponse, error) {
+	var result *api.SimulateBundleResponse
+	err := c.Call(ctx, &result, "contract_simulateBundle", args)
+	return result, err
+}
+
+// ReadonlyCall decodes the decoded contract data into result, the shape of result depends on the format
+func (c *Client) ReadonlyCall(ctx context.Context, args api.ReadonlyCallArgs, stateOverrides api.StateOverrides, result interface{}) error {
+	return c.Call(ctx, result, "contract_readonlyCall", args, stateOverrides)
+}
+
+// ReadData decodes the contract storage value into result, the shape of result depends on the format
+func (c *Client) ReadData(ctx context.Context, contract common.Address, key string, format string, blockNumber *rpc.BlockNumber, result interface{}) error {
+	return c.Call(ctx, result, "contract_readData", contract, key, format, blockNumber)
+}
+
+// ReadMap decodes the value of the contract map into result, the shape of result depends on the format
+func (c *Client) ReadMap(ctx context.Context, contract common.Address, mapName string, key hexutil.Bytes, format string, blockNumber *rpc.BlockNumber, result interface{}) error {
+	return c.Call(ctx, result, "contract_readMap", contract, mapName, key, format, blockNumber)
+}
+
+func (c *Client) IterateMap(ctx context.Context, contract common.Address, mapName string, continuationToken *hexutil.Bytes, keyFormat, valueFormat string, limit int, blockNumber *rpc.BlockNumber, options *api.IterateMapOptions) (*api.IterateMapResponse, error) {
+	var result *api.IterateMapResponse
+	err := c.Call(ctx, &result, "contract_iterateMap", contract, mapName, continuationToken, keyFormat, valueFormat, limit, blockNumber, options)
+	return result, err
+}
+
+func (c *Client) ReadCollection(ctx context.Context, args api.ReadCollectionArgs) (*api.ReadCollectionResponse, error) {
+	var result *api.ReadCollectionResponse
+	err := c.Call(ctx, &result, "contract_readCollection", args)
+	return result, err
+}
+
+func (c *Client) GetStake(ctx context.Context, contract common.Address) (*api.ContractStake, error) {
+	var result *api.ContractStake
+	err := c.Call(ctx, &result, "contract_getStake", contract)
+	return result, err
+}
+
+func (c *Client) Events(ctx context.Context, contract common.Address) ([]*api.Event, error) {
+	var result []*api.Event
+	err := c.Call(ctx, &result, "contract_events", api.EventsArgs{Contract: contract})
+	return result, err
+}
+
+func (c *Client) GetEvents(ctx context.Context, args api.GetEventsArgs) (*api.GetEventsResponse, error) {
+	var result *api.GetEventsResponse
+	err := c.Call(ctx, &result, "contract_getEvents", args)
+	return result, err
+}
+
+func (c *Client) RegisterAbi(ctx context.Context, args api.RegisterAbiArgs) error {
+	return c.Call(ctx, nil, "contract_registerAbi", args)
+}
+
+func (c *Client) GetAbi(ctx context.Context, contract common.Address) (*api.ContractAbi, error) {
+	var result *api.ContractAbi
+	err := c.Call(ctx, &result, "contract_getAbi", contract)
+	return result, err
+}
+
+func (c *Client) List(ctx context.Context) ([]*api.ContractInfo, error) {
+	var result []*api.ContractInfo
+	err := c.Call(ctx, &result, "contract_list")
+	return result, err
+}
+
+func (c *Client) GetCode(ctx context.Context, contract common.Address) (hexutil.Bytes, error) {
+	var result hexutil.Bytes
+	err := c.Call(ctx, &result, "contract_getCode", contract)
+	return result, err
+}
+
+func (c *Client) InspectCode(ctx context.Context, contract common.Address) (*api.CodeInspection, error) {
+	var result *api.CodeInspection
+	err := c.Call(ctx, &result, "contract_inspectCode", contract)
+	return result, err
+}
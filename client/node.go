@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"github.com/idena-network/idena-contract-runner/api"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"math/big"
+)
+
+// dna_* and bcn_* methods mirror the idena-go node api the runner emulates
+
+func (c *Client) DnaState(ctx context.Context) (*api.State, error) {
+	var result *api.State
+	err := c.Call(ctx, &result, "dna_state")
+	return result, err
+}
+
+func (c *Client) DnaCoinbaseAddr(ctx context.Context) (common.Address, error) {
+	var addr common.Address
+	err := c.Call(ctx, &addr, "dna_getCoinbaseAddr")
+	return addr, err
+}
+
+func (c *Client) DnaBalance(ctx context.Context, addr common.Address) (*api.Balance, error) {
+	var result *api.Balance
+	err := c.Call(ctx, &result, "dna_getBalance", addr)
+	return result, err
+}
+
+func (c *Client) DnaSendTransaction(ctx context.Context, args api.SendTxArgs) (common.Hash, error) {
+	var hash common.Hash
+	err := c.Call(ctx, &hash, "dna_sendTransaction", args)
+	return hash, err
+}
+
+func (c *Client) DnaIdentities(ctx context.Context) ([]api.Identity, error) {
+	var result []api.Identity
+	err := c.Call(ctx, &result, "dna_identities")
+	return result, err
+}
+
+// DnaIdentity returns the identity of the address, the coinbase identity is returned if addr is nil
+func (c *Client) DnaIdentity(ctx context.Context, addr *common.Address) (*api.Identity, error) {
+	var result *api.Identity
+	err := c.Call(ctx, &result, "dna_identity", addr)
+	return result, err
+}
+
+func (c *Client) DnaEpoch(ctx context.Context) (*api.Epoch, error) {
+	var result *api.Epoch
+	err := c.Call(ctx, &result, "dna_epoch")
+	return result, err
+}
+
+func (c *Client) DnaCeremonyIntervals(ctx context.Context) (*api.CeremonyIntervals, error) {
+	var result *api.CeremonyIntervals
+	err := c.Call(ctx, &result, "dna_ceremonyIntervals")
+	return result, err
+}
+
+func (c *Client) DnaGlobalState(ctx context.Context) (*api.GlobalState, error) {
+	var result *api.GlobalState
+	err := c.Call(ctx, &result, "dna_globalState")
+	return result, err
+}
+
+func (c *Client) DnaIsValidationReady(ctx context.Context) (bool, error) {
+	var result bool
+	err := c.Call(ctx, &result, "dna_isValidationReady")
+	return result, err
+}
+
+func (c *Client) DnaVersion(ctx context.Context) (string, error) {
+	var result string
+	err := c.Call(ctx, &result, "dna_version")
+	return result, err
+}
+
+// DnaSign signs the value with the coinbase key
+func (c *Client) DnaSign(ctx context.Context, value string) (hexutil.Bytes, error) {
+	var result hexutil.Bytes
+	err := c.Call(ctx, &result, "dna_sign", value)
+	return result, err
+}
+
+func (c *Client) DnaSignatureAddress(ctx context.Context, args api.SignatureAddressArgs) (common.Address, error) {
+	var addr common.Address
+	err := c.Call(ctx, &addr, "dna_signatureAddress", args)
+	return addr, err
+}
+
+func (c *Client) BcnLastBlock(ctx context.Context) (*api.BcnBlock, error) {
+	var block *api.BcnBlock
+	err := c.Call(ctx, &block, "bcn_lastBlock")
+	return block, err
+}
+
+func (c *Client) BcnBlockAt(ctx context.Context, height uint64) (*api.BcnBlock, error) {
+	var block *api.BcnBlock
+	err := c.Call(ctx, &block, "bcn_blockAt", height)
+	return block, err
+}
+
+func (c *Client) BcnBlock(ctx context.Context, hash common.Hash) (*api.BcnBlock, error) {
+	var block *api.BcnBlock
+	err := c.Call(ctx, &block, "bcn_block", hash)
+	return block, err
+}
+
+func (c *Client) BcnTransaction(ctx context.Context, hash common.Hash) (*api.BcnTransaction, error) {
+	var tx *api.BcnTransaction
+	err := c.Call(ctx, &tx, "bcn_transaction", hash)
+	return tx, err
+}
+
+func (c *Client) BcnTxReceipt(ctx context.Context, hash common.Hash) (*api.TxReceipt, error) {
+	var receipt *api.TxReceipt
+	err := c.Call(ctx, &receipt, "bcn_txReceipt", hash)
+	return receipt, err
+}
+
+func (c *Client) BcnMempool(ctx context.Context) ([]common.Hash, error) {
+	var result []common.Hash
+	err := c.Call(ctx, &result, "bcn_mempool")
+	return result, err
+}
+
+func (c *Client) BcnSyncing(ctx context.Context) (*api.Syncing, error) {
+	var result *api.Syncing
+	err := c.Call(ctx, &result, "bcn_syncing")
+	return result, err
+}
+
+func (c *Client) BcnFeePerGas(ctx context.Context) (*big.Int, error) {
+	var result *big.Int
+	err := c.Call(ctx, &result, "bcn_feePerGas")
+	return result, err
+}
+
+func (c *Client) BcnSendRawTx(ctx context.Context, tx hexutil.Bytes) (common.Hash, error) {
+	var hash common.Hash
+	err := c.Call(ctx, &hash, "bcn_sendRawTx", tx)
+	return hash, err
+}
+
+func (c *Client) BcnGetRawTx(ctx context.Context, args api.SendTxArgs) (hexutil.Bytes, error) {
+	var result hexutil.Bytes
+	err := c.Call(ctx, &result, "bcn_getRawTx", args)
+	return result, err
+}
+
+func (c *Client) BcnEstimateTx(ctx context.Context, args api.SendTxArgs) (*api.EstimateTxResponse, error) {
+	var result *api.EstimateTxResponse
+	err := c.Call(ctx, &result, "bcn_estimateTx", args)
+	return result, err
+}
+
+func (c *Client) BcnTransactions(ctx context.Context, args api.TransactionsArgs) (*api.BcnTransactions, error) {
+	var result *api.BcnTransactions
+	err := c.Call(ctx, &result, "bcn_transactions", args)
+	return result, err
+}
+
+func (c *Client) BcnPendingTransactions(ctx context.Context, args api.TransactionsArgs) (*api.BcnTransactions, error) {
+	var result *api.BcnTransactions
+	err := c.Call(ctx, &result, "bcn_pendingTransactions", args)
+	return result, err
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"github.com/idena-network/idena-contract-runner/api"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+)
+
+func (c *Client) sendPredefined(ctx context.Context, method string, args interface{}) (common.Hash, error) {
+	var hash common.Hash
+	err := c.Call(ctx, &hash, "predefined_"+method, args)
+	return hash, err
+}
+
+func (c *Client) PredefinedTerminate(ctx context.Context, args api.PredefinedTerminateArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "terminate", args)
+}
+
+func (c *Client) DeployTimeLock(ctx context.Context, args api.TimeLockDeployArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "deployTimeLock", args)
+}
+
+func (c *Client) TimeLockTransfer(ctx context.Context, args api.TransferArgsWithContract) (common.Hash, error) {
+	return c.sendPredefined(ctx, "timeLockTransfer", args)
+}
+
+func (c *Client) DeployMultisig(ctx context.Context, args api.MultisigDeployArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "deployMultisig", args)
+}
+
+func (c *Client) MultisigAdd(ctx context.Context, args api.MultisigAddArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "multisigAdd", args)
+}
+
+func (c *Client) MultisigSend(ctx context.Context, args api.TransferArgsWithContract) (common.Hash, error) {
+	return c.sendPredefined(ctx, "multisigSend", args)
+}
+
+func (c *Client) MultisigPush(ctx context.Context, args api.TransferArgsWithContract) (common.Hash, error) {
+	return c.sendPredefined(ctx, "multisigPush", args)
+}
+
+func (c *Client) DeployOracleVoting(ctx context.Context, args api.OracleVotingDeployArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "deployOracleVoting", args)
+}
+
+func (c *Client) OracleVotingStartVoting(ctx context.Context, args api.PredefinedCallArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "oracleVotingStartVoting", args)
+}
+
+func (c *Client) OracleVotingSendVoteProof(ctx context.Context, args api.OracleVoteProofArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "oracleVotingSendVoteProof", args)
+}
+
+func (c *Client) OracleVotingSendVote(ctx context.Context, args api.OracleVoteArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "oracleVotingSendVote", args)
+}
+
+func (c *Client) OracleVotingFinishVoting(ctx context.Context, args api.PredefinedCallArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "oracleVotingFinishVoting", args)
+}
+
+func (c *Client) OracleVotingProlongVoting(ctx context.Context, args api.PredefinedCallArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "oracleVotingProlongVoting", args)
+}
+
+func (c *Client) OracleVotingAddStake(ctx context.Context, args api.PredefinedCallArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "oracleVotingAddStake", args)
+}
+
+func (c *Client) OracleVotingVoteHash(ctx context.Context, contract common.Address, vote byte, salt hexutil.Bytes) (hexutil.Bytes, error) {
+	var hash hexutil.Bytes
+	err := c.Call(ctx, &hash, "predefined_oracleVotingVoteHash", contract, vote, salt)
+	return hash, err
+}
+
+func (c *Client) DeployOracleLock(ctx context.Context, args api.OracleLockDeployArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "deployOracleLock", args)
+}
+
+func (c *Client) OracleLockCheckOracleVoting(ctx context.Context, args api.PredefinedCallArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "oracleLockCheckOracleVoting", args)
+}
+
+func (c *Client) OracleLockPush(ctx context.Context, args api.PredefinedCallArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "oracleLockPush", args)
+}
+
+func (c *Client) DeployRefundableOracleLock(ctx context.Context, args api.RefundableOracleLockDeployArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "deployRefundableOracleLock", args)
+}
+
+func (c *Client) RefundableOracleLockDeposit(ctx context.Context, args api.PredefinedCallArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "refundableOracleLockDeposit", args)
+}
+
+func (c *Client) RefundableOracleLockPush(ctx context.Context, args api.PredefinedCallArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "refundableOracleLockPush", args)
+}
+
+func (c *Client) RefundableOracleLockRefund(ctx context.Context, args api.PredefinedCallArgs) (common.Hash, error) {
+	return c.sendPredefined(ctx, "refundableOracleLockRefund", args)
+}
+
+func (c *Client) TimeLockState(ctx context.Context, contract common.Address) (*api.TimeLockState, error) {
+	var result *api.TimeLockState
+	err := c.Call(ctx, &result, "predefined_timeLockState", contract)
+	return result, err
+}
+
+func (c *Client) MultisigState(ctx context.Context, contract common.Address) (*api.MultisigState, error) {
+	var result *api.MultisigState
+	err := c.Call(ctx, &result, "predefined_multisigState", contract)
+	return result, err
+}
+
+func (c *Client) OracleVotingState(ctx context.Context, contract common.Address) (*api.OracleVotingState, error) {
+	var result *api.OracleVotingState
+	err := c.Call(ctx, &result, "predefined_oracleVotingState", contract)
+	return result, err
+}
+
+func (c *Client) OracleLockState(ctx context.Context, contract common.Address) (*api.OracleLockState, error) {
+	var result *api.OracleLockState
+	err := c.Call(ctx, &result, "predefined_oracleLockState", contract)
+	return result, err
+}
+
+func (c *Client) RefundableOracleLockState(ctx context.Context, contract common.Address) (*api.RefundableOracleLockState, error) {
+	var result *api.RefundableOracleLockState
+	err := c.Call(ctx, &result, "predefined_refundableOracleLockState", contract)
+	return result, err
+}
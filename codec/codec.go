@@ -0,0 +1,361 @@
+// Package codec converts human readable values to contract arguments and storage values.
+//
+// Scalars are encoded the same way as in idena-sdk-as: integers are fixed width little-endian, bool is a single byte,
+// bigint and dna are unsigned big-endian magnitudes, address and hash are raw 20 and 32 bytes. Negative bigint and dna
+// values are rejected since the magnitude has no sign. Decoding an integer requires exactly its width. Note that int8
+// is a single byte like byte and uint8, APIs before the codec encoded int8 arguments as 8 bytes.
+// Composite formats are written as
+//
+//	array<T>                  JSON array, encoded as u32 item count followed by length-prefixed items
+//	struct<name:T,name2:T2>   JSON object, encoded as length-prefixed fields in declaration order
+//
+// where every length prefix is a little-endian u32.
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	arrayPrefix  = "array<"
+	structPrefix = "struct<"
+)
+
+type encoder func(value string) ([]byte, error)
+
+type decoder func(data []byte) (interface{}, error)
+
+// codec is a pair of functions converting a value of a scalar format in both directions, the value returned by
+// decode is encoded back to the same bytes when it is passed to encode in its JSON form
+type codec struct {
+	encode encoder
+	decode decoder
+}
+
+var registry = map[string]codec{
+	"byte":    {encodeUint(8), decodeUint(8)},
+	"uint8":   {encodeUint(8), decodeUint(8)},
+	"uint16":  {encodeUint(16), decodeUint(16)},
+	"uint32":  {encodeUint(32), decodeUint(32)},
+	"uint64":  {encodeUint(64), decodeUint(64)},
+	"int8":    {encodeInt(8), decodeInt(8)},
+	"int16":   {encodeInt(16), decodeInt(16)},
+	"int32":   {encodeInt(32), decodeInt(32)},
+	"int64":   {encodeInt(64), decodeInt(64)},
+	"bool":    {encodeBool, decodeBool},
+	"string":  {encodeString, decodeString},
+	"bigint":  {encodeBigInt, decodeBigInt},
+	"dna":     {encodeDna, decodeDna},
+	"hex":     {encodeHex, decodeHex},
+	"base64":  {encodeBase64, decodeBase64},
+	"address": {encodeAddress, decodeAddress},
+	"hash":    {encodeHash, decodeHash},
+}
+
+// Formats returns names of the supported scalar formats
+func Formats() []string {
+	var result []string
+	for name := range registry {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Encode converts the value to bytes according to the format, an empty format means hex
+func Encode(format string, value string) ([]byte, error) {
+	format = normalize(format)
+	if elem, ok := unwrap(format, arrayPrefix); ok {
+		return encodeArray(elem, value)
+	}
+	if fields, ok := unwrap(format, structPrefix); ok {
+		return encodeStruct(fields, value)
+	}
+	c, ok := registry[format]
+	if !ok {
+		return nil, unknownFormat(format)
+	}
+	return c.encode(value)
+}
+
+// Decode converts bytes to a JSON friendly value according to the format, an empty format means hex
+func Decode(format string, data []byte) (interface{}, error) {
+	format = normalize(format)
+	if elem, ok := unwrap(format, arrayPrefix); ok {
+		return decodeArray(elem, data)
+	}
+	if fields, ok := unwrap(format, structPrefix); ok {
+		return decodeStruct(fields, data)
+	}
+	c, ok := registry[format]
+	if !ok {
+		return nil, unknownFormat(format)
+	}
+	return c.decode(data)
+}
+
+// Validate checks the format and the formats nested into it without a value
+func Validate(format string) error {
+	format = normalize(format)
+	if elem, ok := unwrap(format, arrayPrefix); ok {
+		return errors.Wrap(Validate(elem), "array item")
+	}
+	if fieldsFormat, ok := unwrap(format, structPrefix); ok {
+		fields, err := parseFields(fieldsFormat)
+		if err != nil {
+			return err
+		}
+		names := make(map[string]struct{})
+		for _, f := range fields {
+			if _, ok := names[f.name]; ok {
+				return errors.Errorf("duplicate struct field \"%v\"", f.name)
+			}
+			names[f.name] = struct{}{}
+			if err := Validate(f.format); err != nil {
+				return errors.Wrapf(err, "field \"%v\"", f.name)
+			}
+		}
+		return nil
+	}
+	if _, ok := registry[format]; !ok {
+		return unknownFormat(format)
+	}
+	return nil
+}
+
+func normalize(format string) string {
+	format = strings.TrimSpace(format)
+	if format == "" {
+		return "hex"
+	}
+	return format
+}
+
+func encodeUint(bits int) encoder {
+	return func(value string) ([]byte, error) {
+		i, err := strconv.ParseUint(strings.TrimSpace(value), 10, bits)
+		if err != nil {
+			return nil, parseError(uintName(bits), value)
+		}
+		return putUint(i, bits), nil
+	}
+}
+
+func encodeInt(bits int) encoder {
+	return func(value string) ([]byte, error) {
+		i, err := strconv.ParseInt(strings.TrimSpace(value), 10, bits)
+		if err != nil {
+			return nil, parseError("int"+strconv.Itoa(bits), value)
+		}
+		return putUint(uint64(i), bits), nil
+	}
+}
+
+func putUint(i uint64, bits int) []byte {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, i)
+	return data[:bits/8]
+}
+
+func uintName(bits int) string {
+	if bits == 8 {
+		return "byte"
+	}
+	return "uint" + strconv.Itoa(bits)
+}
+
+func encodeBool(value string) ([]byte, error) {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return nil, parseError("bool", value)
+	}
+	if b {
+		return []byte{1}, nil
+	}
+	return []byte{0}, nil
+}
+
+func encodeString(value string) ([]byte, error) {
+	return []byte(value), nil
+}
+
+func encodeBigInt(value string) ([]byte, error) {
+	v := new(big.Int)
+	if _, ok := v.SetString(strings.TrimSpace(value), 10); !ok {
+		return nil, parseError("bigint", value)
+	}
+	if v.Sign() < 0 {
+		return nil, negativeError("bigint", value)
+	}
+	return v.Bytes(), nil
+}
+
+func encodeDna(value string) ([]byte, error) {
+	d, err := decimal.NewFromString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, parseError("dna", value)
+	}
+	if d.IsNegative() {
+		return nil, negativeError("dna", value)
+	}
+	return blockchain.ConvertToInt(d).Bytes(), nil
+}
+
+func encodeHex(value string) ([]byte, error) {
+	data, err := hexutil.Decode(strings.TrimSpace(value))
+	if err != nil {
+		return nil, parseError("hex", value)
+	}
+	return data, nil
+}
+
+func encodeBase64(value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, parseError("base64", value)
+	}
+	return data, nil
+}
+
+func encodeAddress(value string) ([]byte, error) {
+	data, err := hexutil.Decode(strings.TrimSpace(value))
+	if err != nil || len(data) != common.AddressLength {
+		return nil, parseError("address", value)
+	}
+	return data, nil
+}
+
+func encodeHash(value string) ([]byte, error) {
+	data, err := hexutil.Decode(strings.TrimSpace(value))
+	if err != nil || len(data) != common.HashLength {
+		return nil, parseError("hash", value)
+	}
+	return data, nil
+}
+
+func encodeArray(elemFormat string, value string) ([]byte, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		return nil, parseError("array", value)
+	}
+	result := putUint(uint64(len(items)), 32)
+	for i, item := range items {
+		data, err := Encode(elemFormat, RawValue(item))
+		if err != nil {
+			return nil, errors.Wrapf(err, "item %v", i)
+		}
+		result = appendWithLength(result, data)
+	}
+	return result, nil
+}
+
+func encodeStruct(fieldsFormat string, value string) ([]byte, error) {
+	fields, err := parseFields(fieldsFormat)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &obj); err != nil {
+		return nil, parseError("struct", value)
+	}
+	var result []byte
+	for _, f := range fields {
+		raw, ok := obj[f.name]
+		if !ok {
+			return nil, errors.Errorf("missing struct field \"%v\"", f.name)
+		}
+		data, err := Encode(f.format, RawValue(raw))
+		if err != nil {
+			return nil, errors.Wrapf(err, "field \"%v\"", f.name)
+		}
+		result = appendWithLength(result, data)
+	}
+	return result, nil
+}
+
+func appendWithLength(dst []byte, data []byte) []byte {
+	dst = append(dst, putUint(uint64(len(data)), 32)...)
+	return append(dst, data...)
+}
+
+type field struct {
+	name   string
+	format string
+}
+
+func parseFields(format string) ([]field, error) {
+	var result []field
+	for _, part := range splitTopLevel(format) {
+		idx := strings.Index(part, ":")
+		if idx <= 0 {
+			return nil, errors.Errorf("invalid struct field: \"%v\"", part)
+		}
+		result = append(result, field{
+			name:   strings.TrimSpace(part[:idx]),
+			format: strings.TrimSpace(part[idx+1:]),
+		})
+	}
+	return result, nil
+}
+
+// splitTopLevel splits the list by commas which are not nested into angle brackets
+func splitTopLevel(s string) []string {
+	var result []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if strings.TrimSpace(s[start:]) != "" {
+		result = append(result, s[start:])
+	}
+	return result
+}
+
+func unwrap(format string, prefix string) (string, bool) {
+	if strings.HasPrefix(format, prefix) && strings.HasSuffix(format, ">") {
+		return format[len(prefix) : len(format)-1], true
+	}
+	return "", false
+}
+
+// RawValue returns JSON strings unquoted and any other JSON value (numbers, booleans, arrays, objects) as is
+func RawValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func unknownFormat(format string) error {
+	return errors.Errorf("unknown format: \"%v\"", format)
+}
+
+func parseError(format string, value string) error {
+	return errors.Errorf("cannot parse %v: \"%v\"", format, value)
+}
+
+func negativeError(format string, value string) error {
+	return errors.Errorf("%v cannot be negative: \"%v\"", format, value)
+}
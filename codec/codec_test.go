@@ -0,0 +1,141 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	address := "0x" + strings.Repeat("ab", 20)
+	hash := "0x" + strings.Repeat("cd", 32)
+	cases := []struct {
+		format string
+		value  string
+		data   string
+		// json is the JSON form of the decoded value
+		json string
+	}{
+		{"byte", "255", "0xff", `255`},
+		{"uint8", "7", "0x07", `7`},
+		{"uint16", "258", "0x0201", `258`},
+		{"uint32", "1", "0x01000000", `1`},
+		{"uint64", "18446744073709551615", "0xffffffffffffffff", `18446744073709551615`},
+		{"int8", "-1", "0xff", `-1`},
+		{"int16", "-2", "0xfeff", `-2`},
+		{"int32", "-3", "0xfdffffff", `-3`},
+		{"int64", "-4", "0xfcffffffffffffff", `-4`},
+		{"bool", "true", "0x01", `true`},
+		{"bool", "false", "0x00", `false`},
+		{"string", "hello", "0x68656c6c6f", `"hello"`},
+		{"bigint", "1000000000000000000000", "0x3635c9adc5dea00000", `"1000000000000000000000"`},
+		{"bigint", "0", "0x", `"0"`},
+		{"dna", "1.5", "0x14d1120d7b160000", `"1.5"`},
+		{"hex", "0x0102", "0x0102", `"0x0102"`},
+		{"", "0x0102", "0x0102", `"0x0102"`},
+		{"base64", "AQI=", "0x0102", `"AQI="`},
+		{"address", address, address, `"` + address + `"`},
+		{"hash", hash, hash, `"` + hash + `"`},
+		{"array<uint16>", `[1,2]`, "0x02000000020000000100020000000200", `[1,2]`},
+		{"array<string>", `[]`, "0x00000000", `[]`},
+		{"struct<a:uint8,b:string>", `{"a":1,"b":"x"}`, "0x01000000010100000078", `{"a":1,"b":"x"}`},
+		{"array<struct<n:int8>>", `[{"n":-1}]`, "0x010000000500000001000000ff", `[{"n":-1}]`},
+	}
+	for _, c := range cases {
+		t.Run(c.format+" "+c.value, func(t *testing.T) {
+			data, err := Encode(c.format, c.value)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			if hexutil.Encode(data) != c.data {
+				t.Fatalf("encode: expected %v, got %v", c.data, hexutil.Encode(data))
+			}
+			decoded, err := Decode(c.format, data)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			raw, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if !strings.EqualFold(string(raw), c.json) {
+				t.Fatalf("decode: expected %v, got %s", c.json, raw)
+			}
+			again, err := Encode(c.format, RawValue(raw))
+			if err != nil {
+				t.Fatalf("encode decoded: %v", err)
+			}
+			if !bytes.Equal(again, data) {
+				t.Fatalf("encode decoded: expected %v, got %v", c.data, hexutil.Encode(again))
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		format string
+		data   string
+		err    string
+	}{
+		{"unknown format", "float", "0x01", `unknown format: "float"`},
+		{"short uint", "uint32", "0x0102", "uint32: expected 4 bytes, got 2"},
+		{"trailing uint", "uint16", "0x010203", "uint16: expected 2 bytes, got 3"},
+		{"trailing byte", "byte", "0x0102", "byte: expected 1 bytes, got 2"},
+		{"trailing int", "int64", "0x010203040506070809", "int64: expected 8 bytes, got 9"},
+		{"invalid bool", "bool", "0x02", "bool: invalid value 0x02"},
+		{"short address", "address", "0x01", "address: expected 20 bytes, got 1"},
+		{"trailing array", "array<uint8>", "0x000000000a", "array: 1 unexpected trailing bytes"},
+		{"trailing item", "array<uint8>", "0x01000000020000000102", "item 0: byte: expected 1 bytes, got 2"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Decode(c.format, hexutil.MustDecode(c.data))
+			if err == nil || err.Error() != c.err {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+
+	if _, err := Encode("float", "1"); err == nil || err.Error() != `unknown format: "float"` {
+		t.Fatalf("expected unknown format error, got %v", err)
+	}
+	if _, err := Encode("int8", "128"); err == nil {
+		t.Fatal("expected out of range error")
+	}
+	for _, c := range []struct{ format, value, err string }{
+		{"bigint", "-5", `bigint cannot be negative: "-5"`},
+		{"dna", "-0.5", `dna cannot be negative: "-0.5"`},
+		{"array<bigint>", `["1","-1"]`, `item 1: bigint cannot be negative: "-1"`},
+	} {
+		if _, err := Encode(c.format, c.value); err == nil || err.Error() != c.err {
+			t.Fatalf("%v %v: expected error %q, got %v", c.format, c.value, c.err, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		format string
+		err    string
+	}{
+		{"", ""},
+		{"uint64", ""},
+		{"array<struct<a:uint8,b:array<string>>>", ""},
+		{"float", `unknown format: "float"`},
+		{"array<uint8", `unknown format: "array<uint8"`},
+		{"array<float>", `array item: unknown format: "float"`},
+		{"struct<a:uint8,b:array<float>>", `field "b": array item: unknown format: "float"`},
+		{"struct<a:uint8,a:string>", `duplicate struct field "a"`},
+		{"struct<uint8>", `invalid struct field: "uint8"`},
+	}
+	for _, c := range cases {
+		err := Validate(c.format)
+		if c.err == "" && err != nil || c.err != "" && (err == nil || err.Error() != c.err) {
+			t.Fatalf("%v: expected error %q, got %v", c.format, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+	"strconv"
+)
+
+// decodeUint reads the fixed width little-endian value, the data must have exactly the width of the format
+func decodeUint(bits int) decoder {
+	return func(data []byte) (interface{}, error) {
+		i, err := readFixedUint(data, bits)
+		if err != nil {
+			return nil, errors.Wrap(err, uintName(bits))
+		}
+		switch bits {
+		case 8:
+			return uint8(i), nil
+		case 16:
+			return uint16(i), nil
+		case 32:
+			return uint32(i), nil
+		default:
+			return i, nil
+		}
+	}
+}
+
+func decodeInt(bits int) decoder {
+	return func(data []byte) (interface{}, error) {
+		i, err := readFixedUint(data, bits)
+		if err != nil {
+			return nil, errors.Wrap(err, "int"+strconv.Itoa(bits))
+		}
+		switch bits {
+		case 8:
+			return int8(i), nil
+		case 16:
+			return int16(i), nil
+		case 32:
+			return int32(i), nil
+		default:
+			return int64(i), nil
+		}
+	}
+}
+
+func readFixedUint(data []byte, bits int) (uint64, error) {
+	if size := bits / 8; len(data) > size {
+		return 0, errors.Errorf("expected %v bytes, got %v", size, len(data))
+	}
+	return readUint(data, bits)
+}
+
+// readUint reads the fixed width little-endian value from the start of the data
+func readUint(data []byte, bits int) (uint64, error) {
+	size := bits / 8
+	if len(data) < size {
+		return 0, errors.Errorf("expected %v bytes, got %v", size, len(data))
+	}
+	buf := make([]byte, 8)
+	copy(buf, data[:size])
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+func decodeBool(data []byte) (interface{}, error) {
+	if len(data) != 1 || data[0] > 1 {
+		return nil, errors.Errorf("bool: invalid value %v", hexutil.Encode(data))
+	}
+	return data[0] == 1, nil
+}
+
+func decodeString(data []byte) (interface{}, error) {
+	return string(data), nil
+}
+
+func decodeBigInt(data []byte) (interface{}, error) {
+	return new(big.Int).SetBytes(data).String(), nil
+}
+
+func decodeDna(data []byte) (interface{}, error) {
+	return blockchain.ConvertToFloat(new(big.Int).SetBytes(data)), nil
+}
+
+func decodeHex(data []byte) (interface{}, error) {
+	return hexutil.Encode(data), nil
+}
+
+func decodeBase64(data []byte) (interface{}, error) {
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decodeAddress(data []byte) (interface{}, error) {
+	if len(data) != common.AddressLength {
+		return nil, errors.Errorf("address: expected %v bytes, got %v", common.AddressLength, len(data))
+	}
+	return common.BytesToAddress(data), nil
+}
+
+func decodeHash(data []byte) (interface{}, error) {
+	if len(data) != common.HashLength {
+		return nil, errors.Errorf("hash: expected %v bytes, got %v", common.HashLength, len(data))
+	}
+	return common.BytesToHash(data), nil
+}
+
+func decodeArray(elemFormat string, data []byte) (interface{}, error) {
+	count, err := readUint(data, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "array length")
+	}
+	data = data[4:]
+	result := make([]interface{}, 0)
+	for i := uint64(0); i < count; i++ {
+		var item []byte
+		item, data, err = readWithLength(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "item %v", i)
+		}
+		value, err := Decode(elemFormat, item)
+		if err != nil {
+			return nil, errors.Wrapf(err, "item %v", i)
+		}
+		result = append(result, value)
+	}
+	if len(data) > 0 {
+		return nil, errors.Errorf("array: %v unexpected trailing bytes", len(data))
+	}
+	return result, nil
+}
+
+func decodeStruct(fieldsFormat string, data []byte) (interface{}, error) {
+	fields, err := parseFields(fieldsFormat)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{})
+	for _, f := range fields {
+		var item []byte
+		item, data, err = readWithLength(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field \"%v\"", f.name)
+		}
+		value, err := Decode(f.format, item)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field \"%v\"", f.name)
+		}
+		result[f.name] = value
+	}
+	if len(data) > 0 {
+		return nil, errors.Errorf("struct: %v unexpected trailing bytes", len(data))
+	}
+	return result, nil
+}
+
+// readWithLength splits the length-prefixed item off the data
+func readWithLength(data []byte) (item []byte, rest []byte, err error) {
+	size, err := readUint(data, 32)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "length prefix")
+	}
+	data = data[4:]
+	if uint64(len(data)) < size {
+		return nil, nil, errors.Errorf("expected %v bytes, got %v", size, len(data))
+	}
+	return data[:size], data[size:], nil
+}
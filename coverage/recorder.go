@@ -0,0 +1,180 @@
+package coverage
+
+import (
+	"github.com/idena-network/idena-contract-runner/wasm"
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/crypto"
+	models "github.com/idena-network/idena-wasm-binding/lib/protobuf"
+	"google.golang.org/protobuf/proto"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CodeProvider returns the wasm code of the contract in the state the contract was executed against, nil if the
+// address is not a wasm contract.
+type CodeProvider func(contract common.Address) []byte
+
+// Recorder accumulates hit counts of exported wasm contract functions.
+//
+// The wasm runtime doesn't expose execution hooks and instrumenting the code would change both the code hash (and
+// hence the contract address) and the gas consumption, so coverage is tracked at the entry point level only: every
+// exported function entered by a mined transaction, an estimate or a cross-contract call of them, and by a readonly
+// call counts as a hit. Sub calls of readonly calls are not reported by the vm and not counted. Functions that are not
+// exported are never hit, reports count them as internal and leave them out of the total, so thresholds apply to entry
+// points only. There is no line or basic block data. Methods of a nil recorder do nothing.
+type Recorder struct {
+	contracts map[common.Address]*contractCoverage
+	mutex     sync.Mutex
+}
+
+type contractCoverage struct {
+	codeHash common.Hash
+	module   *wasm.Module
+	hits     map[string]uint64
+}
+
+type ContractCoverage struct {
+	Contract  common.Address      `json:"contract"`
+	CodeHash  common.Hash         `json:"codeHash"`
+	Functions []*FunctionCoverage `json:"functions"`
+	Covered   int                 `json:"covered"`
+	Total     int                 `json:"total"`
+	// Internal is the number of functions of the module that are not exported, their hits are not recorded
+	Internal int `json:"internal"`
+}
+
+type FunctionCoverage struct {
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	Index  uint32 `json:"index"`
+	Hits   uint64 `json:"hits"`
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		contracts: make(map[common.Address]*contractCoverage),
+	}
+}
+
+// RecordReceipt counts the top level action of the receipt and all its sub actions, code provides the code of
+// contracts seen for the first time. Receipts without action result belong to predefined contracts and are ignored.
+func (r *Recorder) RecordReceipt(receipt *types.TxReceipt, code CodeProvider) {
+	if r == nil || len(receipt.ActionResult) == 0 {
+		return
+	}
+	actionResult := &models.ActionResult{}
+	if err := proto.Unmarshal(receipt.ActionResult, actionResult); err != nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.recordActionResult(actionResult, code)
+}
+
+func (r *Recorder) recordActionResult(actionResult *models.ActionResult, code CodeProvider) {
+	if actionResult.InputAction != nil {
+		r.hit(common.BytesToAddress(actionResult.Contract), actionResult.InputAction.Method, code)
+	}
+	for _, subAction := range actionResult.SubActionResults {
+		r.recordActionResult(subAction, code)
+	}
+}
+
+// Hit increments the counter of the contract method, code provides the code of contracts seen for the first time.
+func (r *Recorder) Hit(contract common.Address, method string, code CodeProvider) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.hit(contract, method, code)
+}
+
+func (r *Recorder) hit(contract common.Address, method string, codeProvider CodeProvider) {
+	c, ok := r.contracts[contract]
+	if !ok {
+		code := codeProvider(contract)
+		if len(code) == 0 {
+			return
+		}
+		c = &contractCoverage{
+			codeHash: crypto.Hash(code),
+			hits:     make(map[string]uint64),
+		}
+		// unparsable modules are still reported by the names of the called methods
+		c.module, _ = wasm.ParseModule(code)
+		r.contracts[contract] = c
+	}
+	c.hits[method]++
+}
+
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.contracts = make(map[common.Address]*contractCoverage)
+}
+
+func (r *Recorder) Report() []*ContractCoverage {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var result []*ContractCoverage
+	for addr, c := range r.contracts {
+		result = append(result, c.report(addr))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Compare(result[i].Contract.Hex(), result[j].Contract.Hex()) < 0
+	})
+	return result
+}
+
+func (c *contractCoverage) report(addr common.Address) *ContractCoverage {
+	result := &ContractCoverage{
+		Contract: addr,
+		CodeHash: c.codeHash,
+	}
+	reported := make(map[string]struct{})
+	if c.module != nil {
+		exported := make(map[uint32]struct{})
+		for _, e := range c.module.Exports {
+			if e.Kind != wasm.KindFunction {
+				continue
+			}
+			exported[e.Index] = struct{}{}
+			f := &FunctionCoverage{
+				Name:  e.Name,
+				Index: e.Index,
+				Hits:  c.hits[e.Name],
+			}
+			if int(e.Index) < len(c.module.Functions) {
+				f.Symbol = c.module.Functions[e.Index].Name
+			}
+			result.Functions = append(result.Functions, f)
+			reported[e.Name] = struct{}{}
+		}
+		for _, f := range c.module.Functions {
+			if _, ok := exported[f.Index]; !ok && !f.Imported {
+				result.Internal++
+			}
+		}
+	}
+	var unknown []string
+	for method := range c.hits {
+		if _, ok := reported[method]; !ok {
+			unknown = append(unknown, method)
+		}
+	}
+	sort.Strings(unknown)
+	for _, method := range unknown {
+		result.Functions = append(result.Functions, &FunctionCoverage{Name: method, Hits: c.hits[method]})
+	}
+	for _, f := range result.Functions {
+		result.Total++
+		if f.Hits > 0 {
+			result.Covered++
+		}
+	}
+	return result
+}
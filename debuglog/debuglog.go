@@ -0,0 +1,116 @@
+// Package debuglog captures the debug output of wasm contracts.
+//
+// The wasm library prints the output of the contract debug host call to the process stdout from native code and has no
+// hook for it, so the output is captured by redirecting the stdout file descriptor to a pipe while the vm executes.
+// Captures hold a process wide lock, so vm executions are serialized while their output is captured, and anything
+// else the process prints to stdout meanwhile is captured as contract output, the runner logs are written to a copy of
+// the stdout descriptor. The output of sub calls belongs to the top level action.
+//
+// Capturing is supported on linux and darwin only, on other platforms the contract output goes to the stdout and
+// captures return nothing without locking.
+package debuglog
+
+import (
+	"bufio"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/log"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	lock   sync.Mutex
+	stdout = os.Stdout
+	level  = log.LvlInfo
+)
+
+func init() {
+	if !supported {
+		return
+	}
+	if fd, err := dup(int(os.Stdout.Fd())); err == nil {
+		stdout = os.NewFile(uintptr(fd), "stdout")
+	}
+}
+
+// Stdout returns the process stdout which is not redirected by captures, the runner logs are written there
+func Stdout() *os.File {
+	return stdout
+}
+
+// SetLevel sets the log level of contract output lines
+func SetLevel(lvl log.Lvl) {
+	level = lvl
+}
+
+type Capture struct {
+	writer *os.File
+	output chan []string
+}
+
+// Start redirects the stdout until the capture is stopped, the capture must be stopped
+func Start() *Capture {
+	c := &Capture{}
+	if !supported || stdout == os.Stdout {
+		return c
+	}
+	lock.Lock()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		lock.Unlock()
+		return c
+	}
+	if err := dup2(int(writer.Fd()), int(os.Stdout.Fd())); err != nil {
+		reader.Close()
+		writer.Close()
+		lock.Unlock()
+		return c
+	}
+	c.writer = writer
+	c.output = make(chan []string, 1)
+	go func() {
+		var lines []string
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+		}
+		reader.Close()
+		c.output <- lines
+	}()
+	return c
+}
+
+// Stop restores the stdout and returns the captured lines
+func (c *Capture) Stop() []string {
+	if c.writer == nil {
+		return nil
+	}
+	dup2(int(stdout.Fd()), int(os.Stdout.Fd()))
+	c.writer.Close()
+	c.writer = nil
+	lines := <-c.output
+	lock.Unlock()
+	return lines
+}
+
+// Log writes the contract output to the runner log
+func Log(contract common.Address, method string, lines []string) {
+	logger := log.Root()
+	write := logger.Info
+	switch level {
+	case log.LvlTrace:
+		write = logger.Trace
+	case log.LvlDebug:
+		write = logger.Debug
+	case log.LvlWarn:
+		write = logger.Warn
+	case log.LvlError, log.LvlCrit:
+		// crit exits the process
+		write = logger.Error
+	}
+	for _, line := range lines {
+		write("Contract output", "contract", contract.Hex(), "method", method, "line", line)
+	}
+}
@@ -0,0 +1,13 @@
+package debuglog
+
+import "syscall"
+
+const supported = true
+
+func dup(fd int) (int, error) {
+	return syscall.Dup(fd)
+}
+
+func dup2(oldfd, newfd int) error {
+	return syscall.Dup3(oldfd, newfd, 0)
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package debuglog
+
+import "github.com/pkg/errors"
+
+const supported = false
+
+func dup(fd int) (int, error) {
+	return 0, errors.New("not supported")
+}
+
+func dup2(oldfd, newfd int) error {
+	return errors.New("not supported")
+}
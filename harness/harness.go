@@ -0,0 +1,217 @@
+// Package harness runs an isolated in-process chain with the runner apis, contract tests use it directly from go test
+// without the rpc server.
+package harness
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"github.com/idena-network/idena-contract-runner/api"
+	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-contract-runner/coverage"
+	"github.com/idena-network/idena-go/blockchain"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/ipfs"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// Chain is an in-process chain, chains created by New don't share state
+type Chain struct {
+	bc     *chain.MemBlockchain
+	godKey *ecdsa.PrivateKey
+
+	contractApi   *api.ContractApi
+	chainApi      *api.ChainApi
+	predefinedApi *api.PredefinedApi
+	txPoolApi     *api.TxPoolApi
+	debugApi      *api.DebugApi
+}
+
+// Snapshot is a chain height to revert to
+type Snapshot uint64
+
+func New() (*Chain, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate the god key")
+	}
+	bc := chain.NewMemBlockchain(key)
+	baseApi := api.NewBaseApi(bc, bc.KeyStore(), bc.SecStore(), ipfs.NewMemoryIpfsProxy(), bc.TxPool())
+	contractApi := api.NewContractApi(baseApi, bc)
+	return &Chain{
+		bc:            bc,
+		godKey:        key,
+		contractApi:   contractApi,
+		chainApi:      api.NewChainApi(baseApi, bc, bc.TxPool()),
+		predefinedApi: api.NewPredefinedApi(contractApi),
+		txPoolApi:     api.NewTxPoolApi(baseApi, bc, bc.TxPool()),
+		debugApi:      api.NewDebugApi(baseApi, bc),
+	}, nil
+}
+
+// EnableCoverage makes the chain record hits of exported contract functions to a new recorder
+func (c *Chain) EnableCoverage() *coverage.Recorder {
+	recorder := coverage.NewRecorder()
+	c.bc.SetCoverage(recorder)
+	return recorder
+}
+
+func (c *Chain) Blockchain() *chain.MemBlockchain {
+	return c.bc
+}
+
+func (c *Chain) ContractApi() *api.ContractApi {
+	return c.contractApi
+}
+
+func (c *Chain) ChainApi() *api.ChainApi {
+	return c.chainApi
+}
+
+func (c *Chain) PredefinedApi() *api.PredefinedApi {
+	return c.predefinedApi
+}
+
+func (c *Chain) TxPoolApi() *api.TxPoolApi {
+	return c.txPoolApi
+}
+
+func (c *Chain) DebugApi() *api.DebugApi {
+	return c.debugApi
+}
+
+// God returns the address which has the genesis balance and signs transactions without From
+func (c *Chain) God() common.Address {
+	return crypto.PubkeyToAddress(c.godKey.PublicKey)
+}
+
+func (c *Chain) GodKey() *ecdsa.PrivateKey {
+	return c.godKey
+}
+
+// NewAccount generates a key the chain signs transactions of the account with and funds the account from nothing
+func (c *Chain) NewAccount(balance decimal.Decimal) (common.Address, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to generate key")
+	}
+	addr := c.bc.AddKey(key)
+	if balance.IsPositive() {
+		c.AddBalance(addr, balance)
+	}
+	return addr, nil
+}
+
+// AddBalance credits the address, reads see the balance after the next block
+func (c *Chain) AddBalance(addr common.Address, amount decimal.Decimal) {
+	c.bc.AddBalance(addr, amount)
+}
+
+// SetBalance sets the balance of the address, reads see the balance after the next block
+func (c *Chain) SetBalance(addr common.Address, amount decimal.Decimal) {
+	c.bc.SetBalance(addr, amount)
+}
+
+// Balance returns the balance of the address at the head block
+func (c *Chain) Balance(addr common.Address) decimal.Decimal {
+	appState, err := c.bc.ReadonlyAppState()
+	if err != nil {
+		return decimal.Zero
+	}
+	return blockchain.ConvertToFloat(appState.State.GetBalance(addr))
+}
+
+// Deploy sends the deploy tx and mines it
+func (c *Chain) Deploy(args api.DeployArgs) (*api.MinedTxReceipt, error) {
+	return c.contractApi.DeployAndMine(context.Background(), args)
+}
+
+// DeployCode deploys the wasm code with the args and mines the tx
+func (c *Chain) DeployCode(from common.Address, code []byte, args ...*api.DynamicArg) (*api.MinedTxReceipt, error) {
+	return c.Deploy(api.DeployArgs{From: from, Code: code, Args: args})
+}
+
+// Call sends the call tx and mines it
+func (c *Chain) Call(args api.CallArgs) (*api.MinedTxReceipt, error) {
+	return c.contractApi.CallAndMine(context.Background(), args)
+}
+
+// Terminate sends the terminate tx and mines it
+func (c *Chain) Terminate(args api.TerminateArgs) (*api.MinedTxReceipt, error) {
+	return c.contractApi.TerminateAndMine(context.Background(), args)
+}
+
+// Send puts the deploy, call or terminate tx to the mempool without mining, args must be one of api.DeployArgs,
+// api.CallArgs and api.TerminateArgs
+func (c *Chain) Send(args interface{}) (common.Hash, error) {
+	switch a := args.(type) {
+	case api.DeployArgs:
+		return c.contractApi.Deploy(context.Background(), a)
+	case api.CallArgs:
+		return c.contractApi.Call(context.Background(), a)
+	case api.TerminateArgs:
+		return c.contractApi.Terminate(context.Background(), a)
+	default:
+		return common.Hash{}, errors.Errorf("unsupported tx args %T", args)
+	}
+}
+
+// Mine generates blocks with the mempool transactions
+func (c *Chain) Mine(blocks int) {
+	c.bc.GenerateBlocks(blocks)
+}
+
+// Receipt returns the receipt of the mined tx, nil if the tx is not mined
+func (c *Chain) Receipt(hash common.Hash) *api.TxReceipt {
+	return c.chainApi.TxReceipt(hash)
+}
+
+// Read executes the readonly contract method at the head block
+func (c *Chain) Read(args api.ReadonlyCallArgs) (interface{}, error) {
+	return c.contractApi.ReadonlyCall(context.Background(), args, nil)
+}
+
+// ReadData reads the contract storage value at the head block
+func (c *Chain) ReadData(contract common.Address, key string, format string) (interface{}, error) {
+	return c.contractApi.ReadData(contract, key, format, nil)
+}
+
+// ReadMap reads the value of the contract map at the head block
+func (c *Chain) ReadMap(contract common.Address, mapName string, key []byte, format string) (interface{}, error) {
+	return c.contractApi.ReadMap(contract, mapName, hexutil.Bytes(key), format, nil)
+}
+
+// Estimate executes the call without sending a tx
+func (c *Chain) Estimate(args api.CallArgs) (*api.TxReceipt, error) {
+	return c.contractApi.EstimateCall(context.Background(), args, nil)
+}
+
+// AdvanceTime mines a block with the time moved forward by the seconds
+func (c *Chain) AdvanceTime(seconds int64) {
+	c.bc.AdvanceTime(seconds)
+	c.Mine(1)
+}
+
+func (c *Chain) Height() uint64 {
+	return c.bc.Head.Height()
+}
+
+// Snapshot returns the current state to revert to, pending transactions are not a part of the snapshot
+func (c *Chain) Snapshot() Snapshot {
+	return Snapshot(c.Height())
+}
+
+// Revert resets the chain to the snapshot and clears the mempool. Coverage hits recorded after the snapshot are kept,
+// so the coverage of a test suite accumulates over reverts.
+func (c *Chain) Revert(snapshot Snapshot) error {
+	if uint64(snapshot) > c.Height() {
+		return errors.Errorf("snapshot %v is above the head %v", uint64(snapshot), c.Height())
+	}
+	if _, err := c.bc.ResetTo(uint64(snapshot)); err != nil {
+		return err
+	}
+	_, err := c.txPoolApi.Clear()
+	return err
+}
@@ -0,0 +1,337 @@
+package harness
+
+import (
+	"context"
+	"github.com/idena-network/idena-contract-runner/api"
+	"github.com/idena-network/idena-contract-runner/codec"
+	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/core/state"
+	"github.com/idena-network/idena-wasm-binding/tests/testdata"
+	"github.com/shopspring/decimal"
+	"strings"
+	"testing"
+)
+
+func newChain(t *testing.T) *Chain {
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fee per gas of the genesis state is zero, so transactions get no gas until the first block
+	c.Mine(1)
+	return c
+}
+
+func TestDeployCallRevert(t *testing.T) {
+	c := newChain(t)
+	code, err := testdata.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxFee := decimal.NewFromInt(1000)
+
+	snapshot := c.Snapshot()
+	deploy, err := c.Deploy(api.DeployArgs{
+		From:   c.God(),
+		Code:   code,
+		MaxFee: maxFee,
+		Args:   api.DynamicArgs{{Index: 0, Format: "uint64", Value: "1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deploy.Success {
+		t.Fatalf("deploy failed: %v", deploy.Error)
+	}
+	state, err := c.ReadData(deploy.Contract, "STATE", "string")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(state.(string), "result") {
+		t.Fatalf("unexpected contract state %v", state)
+	}
+
+	call, err := c.Call(api.CallArgs{
+		From:     c.God(),
+		Contract: deploy.Contract,
+		Method:   "compute",
+		MaxFee:   maxFee,
+		Args:     api.DynamicArgs{{Index: 0, Format: "uint64", Value: "10"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !call.Success {
+		t.Fatalf("call failed: %v", call.Error)
+	}
+	if call.BlockHeight <= deploy.BlockHeight {
+		t.Fatalf("call is mined at %v, deploy at %v", call.BlockHeight, deploy.BlockHeight)
+	}
+
+	c.Mine(2)
+	if c.Height() != call.BlockHeight+2 {
+		t.Fatalf("expected height %v, got %v", call.BlockHeight+2, c.Height())
+	}
+
+	if err := c.Revert(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if c.Height() != uint64(snapshot) {
+		t.Fatalf("expected height %v after revert, got %v", uint64(snapshot), c.Height())
+	}
+	if _, err := c.ReadData(deploy.Contract, "STATE", "string"); err == nil {
+		t.Fatal("contract state is not reverted")
+	}
+	if err := c.Revert(Snapshot(c.Height() + 1)); err == nil {
+		t.Fatal("revert above the head must fail")
+	}
+}
+
+func TestNewAccount(t *testing.T) {
+	c := newChain(t)
+	addr, err := c.NewAccount(decimal.NewFromInt(10000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Mine(1)
+	if balance := c.Balance(addr); !balance.Equal(decimal.NewFromInt(10000)) {
+		t.Fatalf("expected balance 10000, got %v", balance)
+	}
+	code, err := testdata.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// transactions of the account are signed with its key without the keystore
+	receipt, err := c.Deploy(api.DeployArgs{
+		From:   addr,
+		Code:   code,
+		MaxFee: decimal.NewFromInt(1000),
+		Args:   api.DynamicArgs{{Index: 0, Format: "uint64", Value: "1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Success {
+		t.Fatalf("deploy failed: %v", receipt.Error)
+	}
+}
+
+func TestRegisterAbiValidatesTypes(t *testing.T) {
+	c := newChain(t)
+	contract := c.God()
+	err := c.ContractApi().RegisterAbi(api.RegisterAbiArgs{
+		Contract: &contract,
+		Abi: &api.ContractAbi{Methods: []*api.AbiMethod{
+			{Name: "compute", Args: []*api.AbiParam{{Name: "values", Type: "array<uint65>"}}},
+		}},
+	})
+	if err == nil || err.Error() != `method "compute": arg "values": array item: unknown format: "uint65"` {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if abi := c.ContractApi().GetAbi(contract); abi != nil {
+		t.Fatal("invalid abi must not be registered")
+	}
+}
+
+func TestReadMapWithNestedVector(t *testing.T) {
+	c := newChain(t)
+	contract := common.Address{0x01}
+	u32 := func(v string) string {
+		data, err := codec.Encode("uint32", v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	// map<uint32, string> under "m" with a nested vector of strings under the key 3
+	values := map[string]string{
+		"m" + u32("1"):            "a",
+		"m" + u32("2"):            "b",
+		"m" + u32("3") + "len":    u32("2"),
+		"m" + u32("3") + u32("0"): "x",
+		"m" + u32("3") + u32("1"): "y",
+	}
+	for key, value := range values {
+		c.Blockchain().SetContractData(contract, key, []byte(value))
+	}
+	c.Mine(1)
+	c.Blockchain().CleanDataMiddlewareValues()
+
+	m, err := c.ContractApi().ReadCollection(api.ReadCollectionArgs{
+		Contract: contract, Kind: api.CollectionMap, Prefix: "m", KeyFormat: "uint32", Format: "string",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Length != 2 || m.Skipped != 3 || len(m.Entries) != 2 || m.Entries[1].Key != uint32(2) || m.Entries[1].Value != "b" {
+		t.Fatalf("unexpected map %+v", m)
+	}
+	v, err := c.ContractApi().ReadCollection(api.ReadCollectionArgs{
+		Contract: contract, Kind: api.CollectionVector, Prefix: "m", Format: "string",
+		Path: []*api.CollectionKey{{Format: "uint32", Value: "3"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Length != 2 || len(v.Items) != 2 || v.Items[0] != "x" || v.Items[1] != "y" {
+		t.Fatalf("unexpected vector %+v", v)
+	}
+}
+
+func TestSetIdentityNextBlock(t *testing.T) {
+	c := newChain(t)
+	addr, err := c.NewAccount(decimal.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identityState := func() state.IdentityState {
+		appState, err := c.Blockchain().ReadonlyAppState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return appState.State.GetIdentityState(addr)
+	}
+	c.ChainApi().SetIdentity(addr, state.Verified)
+	if s := identityState(); s != state.Undefined {
+		t.Fatalf("the identity must be set at the next block, got %v", s)
+	}
+	c.Mine(2)
+	if s := identityState(); s != state.Verified {
+		t.Fatalf("expected a verified identity, got %v", s)
+	}
+}
+
+func TestTimeLockTransferAfterAdvance(t *testing.T) {
+	c := newChain(t)
+	ctx := context.Background()
+	maxFee := decimal.NewFromInt(1000)
+	unlock := uint64(c.Blockchain().Head.Time() + 3600)
+
+	hash, err := c.PredefinedApi().DeployTimeLock(ctx, api.TimeLockDeployArgs{
+		PredefinedTxArgs: api.PredefinedTxArgs{From: c.God(), MaxFee: maxFee},
+		Amount:           decimal.NewFromInt(50000),
+		Timestamp:        unlock,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Mine(1)
+	deploy := c.Receipt(hash)
+	if deploy == nil || !deploy.Success {
+		t.Fatalf("deploy failed: %+v", deploy)
+	}
+
+	to := common.Address{0x1}
+	transfer := api.TransferArgsWithContract{
+		PredefinedCallArgs: api.PredefinedCallArgs{
+			PredefinedTxArgs: api.PredefinedTxArgs{From: c.God(), MaxFee: maxFee},
+			Contract:         deploy.Contract,
+			// the deploy amount is the stake, the transferred coins are paid with the call
+			Amount: decimal.NewFromInt(10),
+		},
+		To:    to,
+		Value: decimal.NewFromInt(10),
+	}
+	hash, err = c.PredefinedApi().TimeLockTransfer(ctx, transfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Mine(1)
+	if receipt := c.Receipt(hash); receipt == nil || receipt.Success {
+		t.Fatalf("transfer before the unlock time must fail: %+v", receipt)
+	}
+
+	// the contract reads the time of the block, the tx must run at the advanced time
+	c.AdvanceTime(3600)
+	hash, err = c.PredefinedApi().TimeLockTransfer(ctx, transfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Mine(1)
+	if receipt := c.Receipt(hash); receipt == nil || !receipt.Success {
+		t.Fatalf("transfer after the unlock time failed: %+v", receipt)
+	}
+	if c.Blockchain().Head.Time() < int64(unlock) {
+		t.Fatalf("block time %v is before the unlock time %v", c.Blockchain().Head.Time(), unlock)
+	}
+	if balance := c.Balance(to); !balance.Equal(decimal.NewFromInt(10)) {
+		t.Fatalf("expected balance 10, got %v", balance)
+	}
+}
+
+func TestReplayOutOfGas(t *testing.T) {
+	c := newChain(t)
+	code, err := testdata.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploy, err := c.Deploy(api.DeployArgs{
+		From:   c.God(),
+		Code:   code,
+		MaxFee: decimal.NewFromInt(1000),
+		Args:   api.DynamicArgs{{Index: 0, Format: "uint64", Value: "1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, err := c.Call(api.CallArgs{
+		From:     c.God(),
+		Contract: deploy.Contract,
+		Method:   "compute",
+		MaxFee:   decimal.NewFromInt(1),
+		Args:     api.DynamicArgs{{Index: 0, Format: "uint64", Value: "10"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Success {
+		t.Fatal("call with a low max fee must run out of gas")
+	}
+
+	replay, err := c.DebugApi().ReplayTransaction(*call.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Receipt.Success || replay.Receipt.Error != call.Error || replay.Receipt.GasUsed != call.GasUsed {
+		t.Fatalf("replay %+v differs from the mined receipt %+v", replay.Receipt, call.TxReceipt)
+	}
+}
+
+func TestBundleOutOfGas(t *testing.T) {
+	c := newChain(t)
+	code, err := testdata.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploy, err := c.Deploy(api.DeployArgs{
+		From:   c.God(),
+		Code:   code,
+		MaxFee: decimal.NewFromInt(1000),
+		Args:   api.DynamicArgs{{Index: 0, Format: "uint64", Value: "1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := func(maxFee int64) *api.BundleOperation {
+		return &api.BundleOperation{Type: api.BundleCall, Call: &api.CallArgs{
+			From:     c.God(),
+			Contract: deploy.Contract,
+			Method:   "compute",
+			MaxFee:   decimal.NewFromInt(maxFee),
+			Args:     api.DynamicArgs{{Index: 0, Format: "uint64", Value: "10"}},
+		}}
+	}
+	result, err := c.ContractApi().SimulateBundle(api.SimulateBundleArgs{
+		Operations:    []*api.BundleOperation{call(1000), call(1), call(1000)},
+		StopOnFailure: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success || len(result.Receipts) != 2 {
+		t.Fatalf("expected the bundle to stop at the second operation, got %v receipts", len(result.Receipts))
+	}
+	if !result.Receipts[0].Success || result.Receipts[1].Error != "Out of gas" {
+		t.Fatalf("unexpected receipts %+v, %+v", result.Receipts[0], result.Receipts[1])
+	}
+}
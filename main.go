@@ -1,11 +1,14 @@
 package main
 
 import (
+	"github.com/idena-network/idena-contract-runner/debuglog"
 	"github.com/idena-network/idena-go/config"
 	"github.com/idena-network/idena-go/log"
 	"github.com/urfave/cli/v2"
 	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
 )
 
 var (
@@ -15,8 +18,24 @@ var (
 func main() {
 	app := cli.NewApp()
 	app.Version = version
+	app.Commands = []*cli.Command{runCommand}
 
-	app.Flags = []cli.Flag{}
+	app.Flags = []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "coverage",
+			Usage: "Record hit counts of exported wasm contract functions, available via coverage_report",
+		},
+		&cli.StringFlag{
+			Name:  "contract-log-level",
+			Usage: "Log level of the contract debug output: trace, debug, info, warn or error",
+			Value: "info",
+		},
+		&cli.IntFlag{
+			Name:  "wsport",
+			Usage: "WebSocket RPC listening port",
+			Value: DefaultWSPort,
+		},
+	}
 
 	app.Action = func(context *cli.Context) error {
 		logLvl := log.LvlInfo
@@ -26,16 +45,33 @@ func main() {
 			useLogColor = context.Bool(config.LogColoring.Name)
 		}
 
-		handler := log.LvlFilterHandler(logLvl, log.StreamHandler(os.Stdout, log.TerminalFormat(useLogColor)))
+		contractLogLvl, err := log.LvlFromString(context.String("contract-log-level"))
+		if err != nil {
+			return err
+		}
+		debuglog.SetLevel(contractLogLvl)
+
+		// the stdout is redirected while contracts are executed to capture their output
+		handler := log.LvlFilterHandler(logLvl, log.StreamHandler(debuglog.Stdout(), log.TerminalFormat(useLogColor)))
 
 		log.Root().SetHandler(handler)
 
 		log.Info("Idena contract runner is starting", "version", app.Version)
 
-		runner := NewRunner()
+		runner := NewRunner(&Config{
+			Coverage: context.Bool("coverage"),
+			WSPort:   context.Int("wsport"),
+		})
 		if err := runner.Start(); err != nil {
 			return err
 		}
+		go func() {
+			sigc := make(chan os.Signal, 1)
+			signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+			<-sigc
+			log.Info("Got interrupt, shutting down...")
+			runner.Stop()
+		}()
 		runner.LogBalance()
 		runner.WaitForStop()
 		return nil
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	namespace = "contract_runner"
+
+	// maxContractLabels limits distinct contract and method pairs, other contract calls are labeled as otherLabel
+	maxContractLabels = 200
+	otherLabel        = "other"
+)
+
+// VM execution kinds
+const (
+	VmRun      = "run"
+	VmEstimate = "estimate"
+	VmRead     = "read"
+	VmReplay   = "replay"
+	VmBundle   = "bundle"
+)
+
+// Metrics keeps collectors of a runner in its own registry, so several runners can live in one process
+type Metrics struct {
+	registry *prometheus.Registry
+
+	blockHeight         prometheus.Gauge
+	minedTxs            *prometheus.CounterVec
+	failedContractCalls *prometheus.CounterVec
+	contractGasUsed     *prometheus.CounterVec
+	rpcDuration         *prometheus.HistogramVec
+	vmDuration          *prometheus.HistogramVec
+
+	lock           sync.Mutex
+	contractLabels map[[2]string]struct{}
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		blockHeight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "block_height",
+			Help:      "Height of the head block.",
+		}),
+		minedTxs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mined_txs_total",
+			Help:      "Number of mined transactions by type.",
+		}, []string{"type"}),
+		failedContractCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "failed_contract_calls_total",
+			Help:      "Number of mined contract transactions with a failed receipt.",
+		}, []string{"contract", "method"}),
+		contractGasUsed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "contract_gas_used_total",
+			Help:      "Gas used by mined contract transactions.",
+		}, []string{"contract", "method"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "Latency of HTTP RPC requests by method, batch requests are labeled as batch and unregistered methods as unknown.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 16),
+		}, []string{"method"}),
+		vmDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "vm_execution_seconds",
+			Help:      "Execution time of contract transactions and readonly calls.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16),
+		}, []string{"kind"}),
+		contractLabels: make(map[[2]string]struct{}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.blockHeight,
+		m.minedTxs,
+		m.failedContractCalls,
+		m.contractGasUsed,
+		m.rpcDuration,
+		m.vmDuration,
+	)
+	return m
+}
+
+// Handler serves all runner metrics in the prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterMempoolSize exposes the mempool size, the function is called on every scrape
+func (m *Metrics) RegisterMempoolSize(size func() int) error {
+	return m.registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mempool_size",
+		Help:      "Number of transactions in the mempool.",
+	}, func() float64 {
+		return float64(size())
+	}))
+}
+
+func (m *Metrics) SetBlockHeight(height uint64) {
+	m.blockHeight.Set(float64(height))
+}
+
+func (m *Metrics) AddMinedTx(txType string) {
+	m.minedTxs.WithLabelValues(txType).Inc()
+}
+
+func (m *Metrics) AddContractCall(contract, method string, gasUsed uint64, success bool) {
+	contract, method = m.contractLabel(contract, method)
+	m.contractGasUsed.WithLabelValues(contract, method).Add(float64(gasUsed))
+	if !success {
+		m.failedContractCalls.WithLabelValues(contract, method).Inc()
+	}
+}
+
+// contractLabel keeps the first maxContractLabels pairs as they are and folds the rest into otherLabel
+func (m *Metrics) contractLabel(contract, method string) (string, string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := [2]string{contract, method}
+	if _, ok := m.contractLabels[key]; ok {
+		return contract, method
+	}
+	if len(m.contractLabels) >= maxContractLabels {
+		return otherLabel, otherLabel
+	}
+	m.contractLabels[key] = struct{}{}
+	return contract, method
+}
+
+func (m *Metrics) ObserveRpc(method string, duration time.Duration) {
+	m.rpcDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// ObserveVm records the vm execution time, nil metrics ignore it since chains of the harness run without metrics
+func (m *Metrics) ObserveVm(kind string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.vmDuration.WithLabelValues(kind).Observe(duration.Seconds())
+}
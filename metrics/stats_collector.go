@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"github.com/idena-network/idena-go/blockchain/types"
+	"github.com/idena-network/idena-go/core/appstate"
+	"github.com/idena-network/idena-go/stats/collector"
+	"sync"
+	"time"
+)
+
+// vmCollector times contract transactions applied to a block, the chain reports the receipt right after vm.Run
+// so the time between the start of the tx applying and the receipt is the vm execution time
+type vmCollector struct {
+	collector.StatsCollector
+	metrics *Metrics
+	lock    sync.Mutex
+	start   time.Time
+	tx      *types.Transaction
+}
+
+func NewVmCollector(metrics *Metrics) collector.StatsCollector {
+	return &vmCollector{StatsCollector: collector.NewStatsCollector(), metrics: metrics}
+}
+
+func (c *vmCollector) BeginApplyingTx(tx *types.Transaction, appState *appstate.AppState) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.start, c.tx = time.Now(), tx
+}
+
+func (c *vmCollector) AddTxReceipt(txReceipt *types.TxReceipt, appState *appstate.AppState) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.tx != nil && c.tx.Hash() == txReceipt.TxHash {
+		c.metrics.ObserveVm(VmRun, time.Since(c.start))
+	}
+	c.tx = nil
+}
+
+func (c *vmCollector) CompleteApplyingTx(appState *appstate.AppState) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.tx = nil
+}
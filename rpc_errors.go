@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/idena-network/idena-contract-runner/api"
+	"net/http"
+)
+
+// callbackErrorCode is the code the idena-go rpc server writes for every error returned by an api method
+const callbackErrorCode = -32000
+
+type jsonError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type jsonErrResponse struct {
+	Version string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Error   *jsonError      `json:"error"`
+}
+
+// newErrorCodesHandler writes codes and data of structured api errors to responses of the rpc server, the server
+// passes only messages of returned errors to its codec. Api methods of the request report their errors to a collector
+// of the request context and the server runs requests of a http call one by one, so error responses are matched with
+// the reported errors in order.
+func newErrorCodesHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		collector := &api.ErrorCollector{}
+		response := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(response, r.WithContext(api.WithErrorCollector(r.Context(), collector)))
+
+		body := response.body.Bytes()
+		if !collector.Empty() {
+			body = writeErrorCodes(body, collector)
+		}
+		w.WriteHeader(response.status)
+		w.Write(body)
+	})
+}
+
+// bufferedResponse keeps the response of the rpc server until error codes are written
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *bufferedResponse) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
+
+// writeErrorCodes replaces generic errors of the single or batch response with the reported ones, the body is kept
+// as is if it is not a json rpc response
+func writeErrorCodes(body []byte, collector *api.ErrorCollector) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return body
+		}
+		for i, msg := range batch {
+			batch[i] = writeErrorCode(msg, collector)
+		}
+		data, err := json.Marshal(batch)
+		if err != nil {
+			return body
+		}
+		return append(data, '\n')
+	}
+	data := writeErrorCode(trimmed, collector)
+	return append(data, '\n')
+}
+
+func writeErrorCode(msg json.RawMessage, collector *api.ErrorCollector) json.RawMessage {
+	var response jsonErrResponse
+	if err := json.Unmarshal(msg, &response); err != nil || response.Error == nil || response.Error.Code != callbackErrorCode {
+		return msg
+	}
+	rpcErr := collector.Take(response.Error.Message)
+	if rpcErr == nil {
+		return msg
+	}
+	response.Error = &jsonError{Code: rpcErr.ErrorCode(), Message: rpcErr.Error(), Data: rpcErr.ErrorData()}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return msg
+	}
+	return data
+}
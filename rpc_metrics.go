@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/idena-network/idena-contract-runner/metrics"
+	"github.com/idena-network/idena-go/rpc"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"time"
+	"unicode"
+)
+
+const (
+	metricsPath = "/metrics"
+
+	// maxMeasuredRequestSize limits the body read to find the rpc method, larger requests are labeled as unknown
+	maxMeasuredRequestSize = 5 * 1024 * 1024
+
+	unknownMethod = "unknown"
+)
+
+// newMetricsHandler serves prometheus metrics on /metrics and measures the latency of rpc requests. Methods out of
+// the registered ones are labeled as unknown, so clients can not grow the label set.
+func newMetricsHandler(m *metrics.Metrics, methods map[string]bool, next http.Handler) http.Handler {
+	metricsHandler := m.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == metricsPath {
+			metricsHandler.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodPost || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxMeasuredRequestSize+1))
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		m.ObserveRpc(rpcMethod(body, methods), time.Since(start))
+	})
+}
+
+func rpcMethod(body []byte, methods map[string]bool) string {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return "batch"
+	}
+	var msg struct {
+		Method string `json:"method"`
+	}
+	if len(body) > maxMeasuredRequestSize || json.Unmarshal(trimmed, &msg) != nil || !methods[msg.Method] {
+		return unknownMethod
+	}
+	return msg.Method
+}
+
+// addRpcMethods adds the names the rpc server serves the exported methods of the service with
+func addRpcMethods(methods map[string]bool, namespace string, service interface{}) {
+	typ := reflect.TypeOf(service)
+	for i := 0; i < typ.NumMethod(); i++ {
+		name := []rune(typ.Method(i).Name)
+		name[0] = unicode.ToLower(name[0])
+		methods[namespace+"_"+string(name)] = true
+	}
+}
+
+// rpcMethods returns the methods of the apis with the builtin ones of the rpc server
+func rpcMethods(apis []rpc.API) map[string]bool {
+	methods := map[string]bool{rpc.MetadataApi + "_modules": true}
+	for _, api := range apis {
+		addRpcMethods(methods, api.Namespace, api.Service)
+	}
+	return methods
+}
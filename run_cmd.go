@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/idena-network/idena-contract-runner/debuglog"
+	"github.com/idena-network/idena-contract-runner/scenario"
+	"github.com/idena-network/idena-go/log"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"os"
+)
+
+var runCommand = &cli.Command{
+	Name:      "run",
+	Usage:     "Run YAML or JSON scenario files, every scenario runs on its own chain",
+	ArgsUsage: "<scenario files>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "junit",
+			Usage: "Write the results as JUnit XML to the file",
+		},
+		&cli.StringFlag{
+			Name:  "log-level",
+			Usage: "Log level of the chain: trace, debug, info, warn or error",
+			Value: "warn",
+		},
+	},
+	Action: runScenarios,
+}
+
+func runScenarios(context *cli.Context) error {
+	if context.NArg() == 0 {
+		return errors.New("no scenario files")
+	}
+	logLvl, err := log.LvlFromString(context.String("log-level"))
+	if err != nil {
+		return err
+	}
+	log.Root().SetHandler(log.LvlFilterHandler(logLvl, log.StreamHandler(debuglog.Stdout(), log.TerminalFormat(false))))
+
+	var results []*scenario.Result
+	for _, path := range context.Args().Slice() {
+		results = append(results, scenario.RunFile(path))
+	}
+	scenario.WriteSummary(os.Stdout, results)
+
+	if path := context.String("junit"); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := scenario.WriteJUnit(file, results); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	for _, result := range results {
+		if result.Failed() {
+			return cli.Exit("", 1)
+		}
+	}
+	return nil
+}
@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"github.com/idena-network/idena-contract-runner/api"
 	"github.com/idena-network/idena-contract-runner/chain"
+	"github.com/idena-network/idena-contract-runner/coverage"
+	"github.com/idena-network/idena-contract-runner/metrics"
 	"github.com/idena-network/idena-go/blockchain"
 	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-go/common/eventbus"
 	"github.com/idena-network/idena-go/common/hexutil"
 	"github.com/idena-network/idena-go/core/mempool"
 	"github.com/idena-network/idena-go/core/state"
 	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-go/events"
 	"github.com/idena-network/idena-go/ipfs"
 	"github.com/idena-network/idena-go/log"
 	"github.com/idena-network/idena-go/rpc"
@@ -17,15 +21,30 @@ import (
 	"strings"
 )
 
+const DefaultWSPort = 3334
+
+type Config struct {
+	// Coverage enables recording of wasm contract function hits
+	Coverage bool
+	// WSPort is the port of the websocket endpoint, the endpoint is opened on the host of the http endpoint
+	WSPort int
+}
+
 type Runner struct {
+	cfg          *Config
 	chain        *chain.MemBlockchain
+	coverage     *coverage.Recorder
+	metrics      *metrics.Metrics
 	stop         chan struct{}
 	httpListener net.Listener
 	httpServer   *rpc.Server
+	wsListener   net.Listener
+	wsServer     *rpc.Server
 }
 
-func NewRunner() *Runner {
+func NewRunner(cfg *Config) *Runner {
 	return &Runner{
+		cfg:  cfg,
 		stop: make(chan struct{}),
 	}
 }
@@ -35,24 +54,79 @@ func (r *Runner) Start() error {
 	log.Info("Generated god address", "addr", crypto.PubkeyToAddress(key.PublicKey).Hex(), "key", hexutil.Encode(crypto.FromECDSA(key)))
 
 	r.chain = chain.NewMemBlockchain(key)
+	if r.cfg.Coverage {
+		r.startCoverage()
+	}
+	r.startMetrics()
 	if err := r.startRPC(); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (r *Runner) startCoverage() {
+	r.coverage = coverage.NewRecorder()
+	r.chain.SetCoverage(r.coverage)
+	log.Info("Contract coverage recording is enabled")
+}
+
+func (r *Runner) startMetrics() {
+	r.metrics = metrics.New()
+	r.chain.SetMetrics(r.metrics)
+	r.chain.SetStatsCollector(metrics.NewVmCollector(r.metrics))
+	if err := r.metrics.RegisterMempoolSize(func() int {
+		return len(r.TxPool().GetPendingTransaction(true, true, common.MultiShard, false))
+	}); err != nil {
+		log.Warn("Failed to register the mempool size metric", "err", err)
+	}
+	r.metrics.SetBlockHeight(r.chain.Head.Height())
+	r.chain.Bus().Subscribe(events.AddBlockEventID, func(e eventbus.Event) {
+		block := e.(*events.NewBlockEvent).Block
+		r.metrics.SetBlockHeight(block.Height())
+		for _, tx := range block.Body.Transactions {
+			r.metrics.AddMinedTx(api.TxTypeName(tx.Type))
+			receipt := r.chain.GetReceipt(tx.Hash())
+			if receipt == nil {
+				continue
+			}
+			method := receipt.Method
+			if method == "" {
+				method = api.TxTypeName(tx.Type)
+			}
+			r.metrics.AddContractCall(receipt.ContractAddress.Hex(), method, receipt.GasUsed, receipt.Success)
+		}
+	})
+}
+
 func (r *Runner) WaitForStop() {
 	<-r.stop
 }
 
+// Stop closes the rpc endpoints and releases WaitForStop
+func (r *Runner) Stop() {
+	if r.httpListener != nil {
+		r.httpListener.Close()
+		r.httpServer.Stop()
+	}
+	if r.wsListener != nil {
+		r.wsListener.Close()
+		r.wsServer.Stop()
+	}
+	close(r.stop)
+}
+
 func (r *Runner) startRPC() error {
 	// Gather all the possible APIs to surface
 	apis := r.apis()
 	cfg := rpc.GetDefaultRPCConfig("localhost", 3333)
-	cfg.HTTPModules = append(cfg.HTTPModules, "chain")
+	cfg.HTTPModules = append(cfg.HTTPModules, "chain", "coverage", "debug", "predefined", "txpool")
 	if err := r.startHTTP(cfg.HTTPEndpoint(), apis, cfg.HTTPModules, cfg.HTTPCors, cfg.HTTPVirtualHosts, cfg.HTTPTimeouts, cfg.APIKey); err != nil {
 		return err
 	}
+	wsModules := append(append([]string{}, cfg.HTTPModules...), "events")
+	if err := r.startWS(fmt.Sprintf("%s:%d", cfg.HTTPHost, r.cfg.WSPort), apis, wsModules, cfg.HTTPCors); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -61,11 +135,30 @@ func (r *Runner) startHTTP(endpoint string, apis []rpc.API, modules []string, co
 	if endpoint == "" {
 		return nil
 	}
-	listener, httpServer, _, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, apiKey)
+	whitelist := make(map[string]bool)
+	for _, module := range modules {
+		whitelist[module] = true
+	}
+	httpServer := rpc.NewServer(apiKey)
+	var registered []rpc.API
+	for _, api := range apis {
+		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
+			if err := httpServer.RegisterName(api.Namespace, api.Service); err != nil {
+				return err
+			}
+			registered = append(registered, api)
+		}
+	}
+	listener, err := net.Listen("tcp", endpoint)
 	if err != nil {
 		return err
 	}
-	log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
+	// the http server is started here instead of rpc.StartHTTPEndpoint to write codes of structured api errors and
+	// to serve metrics
+	server := rpc.NewHTTPServer(cors, vhosts, timeouts, httpServer)
+	server.Handler = newMetricsHandler(r.metrics, rpcMethods(registered), newErrorCodesHandler(server.Handler))
+	go server.Serve(listener)
+	log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "metrics", fmt.Sprintf("http://%s%s", endpoint, metricsPath), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
 
 	r.httpListener = listener
 	r.httpServer = httpServer
@@ -73,16 +166,32 @@ func (r *Runner) startHTTP(endpoint string, apis []rpc.API, modules []string, co
 	return nil
 }
 
+// startWS opens the websocket endpoint, errors of api methods are returned with the generic code there
+func (r *Runner) startWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string) error {
+	listener, wsServer, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, false)
+	if err != nil {
+		return err
+	}
+	log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", endpoint))
+
+	r.wsListener = listener
+	r.wsServer = wsServer
+
+	return nil
+}
+
 // apis returns the collection of RPC descriptors this node offers.
 func (r *Runner) apis() []rpc.API {
 
 	baseApi := api.NewBaseApi(r.chain, r.chain.KeyStore(), r.chain.SecStore(), ipfs.NewMemoryIpfsProxy(), r.TxPool())
 
-	return []rpc.API{
+	contractApi := api.NewContractApi(baseApi, r.chain)
+
+	apis := []rpc.API{
 		{
 			Namespace: "contract",
 			Version:   "1.0",
-			Service:   api.NewContractApi(baseApi, r.chain),
+			Service:   contractApi,
 			Public:    true,
 		},
 		{
@@ -91,7 +200,52 @@ func (r *Runner) apis() []rpc.API {
 			Service:   api.NewChainApi(baseApi, r.chain, r.TxPool()),
 			Public:    true,
 		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   api.NewDebugApi(baseApi, r.chain),
+			Public:    true,
+		},
+		{
+			Namespace: "events",
+			Version:   "1.0",
+			Service:   api.NewEventsApi(baseApi, r.chain),
+			Public:    true,
+		},
+		{
+			Namespace: "dna",
+			Version:   "1.0",
+			Service:   api.NewDnaApi(baseApi, r.chain, version),
+			Public:    true,
+		},
+		{
+			Namespace: "bcn",
+			Version:   "1.0",
+			Service:   api.NewBcnApi(baseApi, r.chain, r.TxPool()),
+			Public:    true,
+		},
+		{
+			Namespace: "txpool",
+			Version:   "1.0",
+			Service:   api.NewTxPoolApi(baseApi, r.chain, r.TxPool()),
+			Public:    true,
+		},
+		{
+			Namespace: "predefined",
+			Version:   "1.0",
+			Service:   api.NewPredefinedApi(contractApi),
+			Public:    true,
+		},
+	}
+	if r.coverage != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "coverage",
+			Version:   "1.0",
+			Service:   api.NewCoverageApi(r.coverage),
+			Public:    true,
+		})
 	}
+	return apis
 }
 
 func (r *Runner) TxPool() *mempool.TxPool {
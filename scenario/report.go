@@ -0,0 +1,114 @@
+package scenario
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	File     string           `xml:"file,attr,omitempty"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnit writes the results as JUnit XML, scenarios are test suites and steps are test cases
+func WriteJUnit(w io.Writer, results []*Result) error {
+	report := &junitTestSuites{}
+	var total time.Duration
+	for _, result := range results {
+		passed, failed, skipped := result.Count()
+		suite := &junitTestSuite{
+			Name:     result.Name,
+			File:     result.File,
+			Tests:    passed + failed + skipped,
+			Failures: failed,
+			Skipped:  skipped,
+			Time:     seconds(result.Duration),
+		}
+		for _, step := range result.Steps {
+			testCase := &junitTestCase{
+				Name:      step.Name,
+				ClassName: result.Name,
+				Time:      seconds(step.Duration),
+			}
+			if step.Failure != "" {
+				testCase.Failure = &junitFailure{Message: step.Failure, Text: step.Failure}
+			}
+			if step.Skipped {
+				testCase.Skipped = &struct{}{}
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		report.Tests += suite.Tests
+		report.Failures += failed
+		report.Skipped += skipped
+		total += result.Duration
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = seconds(total)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteSummary writes a console summary with the failed steps
+func WriteSummary(w io.Writer, results []*Result) {
+	var passedScenarios, failedScenarios int
+	for _, result := range results {
+		passed, failed, skipped := result.Count()
+		status := "PASS"
+		if result.Failed() {
+			status = "FAIL"
+			failedScenarios++
+		} else {
+			passedScenarios++
+		}
+		fmt.Fprintf(w, "%s %s (%d passed, %d failed, %d skipped, %s)\n", status, result.Name, passed, failed, skipped,
+			result.Duration.Round(time.Millisecond))
+		for _, step := range result.Steps {
+			if step.Failure != "" {
+				fmt.Fprintf(w, "    step %s: %s\n", step.Name, step.Failure)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n%d scenarios, %d passed, %d failed\n", len(results), passedScenarios, failedScenarios)
+}
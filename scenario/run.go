@@ -0,0 +1,458 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-contract-runner/api"
+	"github.com/idena-network/idena-contract-runner/harness"
+	"github.com/idena-network/idena-go/common"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const godName = "god"
+
+type StepResult struct {
+	Name     string
+	Duration time.Duration
+	// Failure is empty for passed and skipped steps
+	Failure string
+	Skipped bool
+}
+
+type Result struct {
+	Name     string
+	File     string
+	Duration time.Duration
+	Steps    []*StepResult
+}
+
+func (r *Result) Failed() bool {
+	for _, step := range r.Steps {
+		if step.Failure != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Result) Count() (passed, failed, skipped int) {
+	for _, step := range r.Steps {
+		switch {
+		case step.Failure != "":
+			failed++
+		case step.Skipped:
+			skipped++
+		default:
+			passed++
+		}
+	}
+	return
+}
+
+// runner executes steps of a single scenario on its own chain
+type runner struct {
+	chain *harness.Chain
+	dir   string
+	names map[string]common.Address
+}
+
+// stepOutcome is what assertions are checked against
+type stepOutcome struct {
+	receipt *api.TxReceipt
+	value   interface{}
+	err     error
+}
+
+// RunFile loads and runs the scenario, a load error is reported as a failed step
+func RunFile(path string) *Result {
+	s, err := Load(path)
+	if err != nil {
+		return &Result{
+			Name:  strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			File:  path,
+			Steps: []*StepResult{{Name: "load", Failure: err.Error()}},
+		}
+	}
+	result := Run(s, filepath.Dir(path))
+	result.File = path
+	return result
+}
+
+// Run executes the steps on a new chain, steps after the first failed one are skipped
+func Run(s *Scenario, dir string) *Result {
+	start := time.Now()
+	result := &Result{Name: s.Name}
+	chain, err := harness.New()
+	if err != nil {
+		result.Steps = []*StepResult{{Name: "chain", Failure: err.Error()}}
+		return result
+	}
+	r := &runner{
+		chain: chain,
+		dir:   dir,
+		names: map[string]common.Address{},
+	}
+	failed := false
+	for i, step := range s.Steps {
+		stepResult := &StepResult{Name: fmt.Sprintf("%d %s", i+1, step.title())}
+		result.Steps = append(result.Steps, stepResult)
+		if failed {
+			stepResult.Skipped = true
+			continue
+		}
+		stepStart := time.Now()
+		if err := r.runStep(step); err != nil {
+			stepResult.Failure = err.Error()
+			failed = true
+		}
+		stepResult.Duration = time.Since(stepStart)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (r *runner) runStep(step *Step) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = errors.Errorf("panic: %v", e)
+		}
+	}()
+	var balances map[common.Address]decimal.Decimal
+	if step.Expect != nil && len(step.Expect.BalanceDeltas) > 0 {
+		balances = r.balances(step.Expect.BalanceDeltas)
+	}
+	outcome, err := r.runAction(step)
+	if err != nil {
+		return err
+	}
+	if step.Expect == nil {
+		if outcome.err != nil {
+			return outcome.err
+		}
+		if outcome.receipt != nil && !outcome.receipt.Success {
+			return errors.Errorf("tx failed: %v", outcome.receipt.Error)
+		}
+		return nil
+	}
+	return r.check(step.Expect, outcome, balances)
+}
+
+func (r *runner) runAction(step *Step) (*stepOutcome, error) {
+	switch {
+	case step.CreateAccount != nil:
+		return &stepOutcome{}, r.createAccount(step.CreateAccount)
+	case step.SetBalance != nil:
+		return &stepOutcome{}, r.setBalance(step.SetBalance)
+	case step.Deploy != nil:
+		return r.deploy(step.Deploy)
+	case step.Call != nil:
+		return r.call(step.Call)
+	case step.Mine > 0:
+		r.chain.Mine(step.Mine)
+		return &stepOutcome{}, nil
+	case step.AdvanceTime > 0:
+		r.chain.AdvanceTime(step.AdvanceTime)
+		return &stepOutcome{}, nil
+	default:
+		return r.read(step.Read)
+	}
+}
+
+func (r *runner) createAccount(args *CreateAccount) error {
+	if args.Name == "" {
+		return errors.New("account name is empty")
+	}
+	if _, ok := r.names[args.Name]; ok || args.Name == godName {
+		return errors.Errorf("name %v is already used", args.Name)
+	}
+	balance, err := parseAmount(args.Balance)
+	if err != nil {
+		return err
+	}
+	addr, err := r.chain.NewAccount(balance)
+	if err != nil {
+		return err
+	}
+	r.names[args.Name] = addr
+	r.chain.Mine(1)
+	return nil
+}
+
+func (r *runner) setBalance(args *SetBalance) error {
+	addr, err := r.address(args.Account)
+	if err != nil {
+		return err
+	}
+	balance, err := parseAmount(args.Balance)
+	if err != nil {
+		return err
+	}
+	r.chain.SetBalance(addr, balance)
+	r.chain.Mine(1)
+	return nil
+}
+
+func (r *runner) deploy(args *Deploy) (*stepOutcome, error) {
+	if args.Name != "" {
+		if _, ok := r.names[args.Name]; ok || args.Name == godName {
+			return nil, errors.Errorf("name %v is already used", args.Name)
+		}
+	}
+	from, err := r.sender(args.From)
+	if err != nil {
+		return nil, err
+	}
+	path := args.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.dir, path)
+	}
+	code, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(args.Amount)
+	if err != nil {
+		return nil, err
+	}
+	maxFee, err := parseAmount(args.MaxFee)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := r.chain.Deploy(api.DeployArgs{
+		From:   from,
+		Code:   code,
+		Amount: amount,
+		MaxFee: maxFee,
+		Args:   r.dynamicArgs(args.Args),
+	})
+	if err != nil {
+		return &stepOutcome{err: err}, nil
+	}
+	if args.Name != "" && receipt.Success {
+		r.names[args.Name] = receipt.Contract
+	}
+	return &stepOutcome{receipt: receipt.TxReceipt}, nil
+}
+
+func (r *runner) call(args *Call) (*stepOutcome, error) {
+	from, err := r.sender(args.From)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := r.address(args.Contract)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(args.Amount)
+	if err != nil {
+		return nil, err
+	}
+	maxFee, err := parseAmount(args.MaxFee)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := r.chain.Call(api.CallArgs{
+		From:     from,
+		Contract: contract,
+		Method:   args.Method,
+		Amount:   amount,
+		MaxFee:   maxFee,
+		Args:     r.dynamicArgs(args.Args),
+	})
+	if err != nil {
+		return &stepOutcome{err: err}, nil
+	}
+	return &stepOutcome{receipt: receipt.TxReceipt}, nil
+}
+
+func (r *runner) read(args *Read) (*stepOutcome, error) {
+	contract, err := r.address(args.Contract)
+	if err != nil {
+		return nil, err
+	}
+	value, err := r.chain.Read(api.ReadonlyCallArgs{
+		Contract: contract,
+		Method:   args.Method,
+		Format:   args.Format,
+		Args:     r.dynamicArgs(args.Args),
+	})
+	return &stepOutcome{value: value, err: err}, nil
+}
+
+func (r *runner) check(expect *Expect, outcome *stepOutcome, balances map[common.Address]decimal.Decimal) error {
+	success := outcome.err == nil && (outcome.receipt == nil || outcome.receipt.Success)
+	if expect.Success != nil && *expect.Success != success {
+		if success {
+			return errors.New("expected a failure, the step succeeded")
+		}
+		return errors.Errorf("expected a success, the step failed: %v", outcomeError(outcome))
+	}
+	if expect.Success == nil && expect.Error == "" && !success {
+		return errors.Errorf("the step failed: %v", outcomeError(outcome))
+	}
+	if expect.Error != "" {
+		if actual := outcomeError(outcome); !strings.Contains(actual, expect.Error) {
+			return errors.Errorf("expected an error containing %q, got %q", expect.Error, actual)
+		}
+	}
+	if expect.Value != nil {
+		if actual := valueString(outcome.value); actual != *expect.Value {
+			return errors.Errorf("expected value %v, got %v", *expect.Value, actual)
+		}
+	}
+	for _, event := range expect.Events {
+		if err := r.checkEvent(event, outcome.receipt); err != nil {
+			return err
+		}
+	}
+	for _, storage := range expect.Storage {
+		if err := r.checkStorage(storage); err != nil {
+			return err
+		}
+	}
+	for _, balance := range expect.BalanceDeltas {
+		if err := r.checkBalance(balance, balances); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *runner) checkEvent(expected ExpectedEvent, receipt *api.TxReceipt) error {
+	if receipt == nil {
+		return errors.Errorf("event %v is expected from a step without receipt", expected.Name)
+	}
+	contract := receipt.Contract
+	if expected.Contract != "" {
+		addr, err := r.address(expected.Contract)
+		if err != nil {
+			return err
+		}
+		contract = addr
+	}
+	for _, event := range receipt.Events {
+		if event.Event == expected.Name && event.Contract == contract {
+			return nil
+		}
+	}
+	return errors.Errorf("event %v of %v is not emitted", expected.Name, contract.Hex())
+}
+
+func (r *runner) checkStorage(expected ExpectedStorage) error {
+	contract, err := r.address(expected.Contract)
+	if err != nil {
+		return err
+	}
+	value, err := r.chain.ReadData(contract, expected.Key, expected.Format)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %v", expected.Key)
+	}
+	if actual := valueString(value); actual != expected.Value {
+		return errors.Errorf("expected %v of %v to be %v, got %v", expected.Key, expected.Contract, expected.Value, actual)
+	}
+	return nil
+}
+
+func (r *runner) checkBalance(expected ExpectedBalance, before map[common.Address]decimal.Decimal) error {
+	addr, err := r.address(expected.Account)
+	if err != nil {
+		return err
+	}
+	delta, err := decimal.NewFromString(expected.Delta)
+	if err != nil {
+		return errors.Wrapf(err, "invalid balance delta %v", expected.Delta)
+	}
+	actual := r.chain.Balance(addr).Sub(before[addr])
+	if !actual.Equal(delta) {
+		return errors.Errorf("expected balance delta of %v to be %v, got %v", expected.Account, delta, actual)
+	}
+	return nil
+}
+
+func (r *runner) balances(expected []ExpectedBalance) map[common.Address]decimal.Decimal {
+	result := map[common.Address]decimal.Decimal{}
+	for _, balance := range expected {
+		addr, err := r.address(balance.Account)
+		if err != nil {
+			// contracts deployed by the step are resolved after it, their balances start with zero
+			continue
+		}
+		result[addr] = r.chain.Balance(addr)
+	}
+	return result
+}
+
+// address resolves the name, god or the hex address
+func (r *runner) address(ref string) (common.Address, error) {
+	if ref == godName {
+		return r.chain.God(), nil
+	}
+	if addr, ok := r.names[ref]; ok {
+		return addr, nil
+	}
+	if common.IsHexAddress(ref) {
+		return common.HexToAddress(ref), nil
+	}
+	return common.Address{}, errors.Errorf("unknown account or contract %q", ref)
+}
+
+// sender resolves the tx sender, god sends transactions without from
+func (r *runner) sender(ref string) (common.Address, error) {
+	if ref == "" {
+		return r.chain.God(), nil
+	}
+	return r.address(ref)
+}
+
+func (r *runner) dynamicArgs(args []Arg) api.DynamicArgs {
+	var result api.DynamicArgs
+	for i, arg := range args {
+		value := arg.Value
+		if arg.Format == "address" {
+			if addr, err := r.address(value); err == nil {
+				value = addr.Hex()
+			}
+		}
+		result = append(result, &api.DynamicArg{Index: i, Format: arg.Format, Value: value})
+	}
+	return result
+}
+
+func parseAmount(value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	amount, err := decimal.NewFromString(value)
+	return amount, errors.Wrapf(err, "invalid amount %v", value)
+}
+
+func outcomeError(outcome *stepOutcome) string {
+	if outcome.err != nil {
+		return outcome.err.Error()
+	}
+	if outcome.receipt != nil {
+		return outcome.receipt.Error
+	}
+	return ""
+}
+
+func valueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,93 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/idena-network/idena-wasm-binding/tests/testdata"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// scenarioDir copies the scenario next to the sum contract of the binding tests
+func scenarioDir(t *testing.T, file string) string {
+	dir := t.TempDir()
+	code, err := testdata.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "sum.wasm"), code, 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, file)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func junit(t *testing.T, results ...*Result) *junitTestSuites {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid junit output: %v\n%s", err, buf.String())
+	}
+	return &report
+}
+
+func TestRunFile(t *testing.T) {
+	path := scenarioDir(t, "sum.yaml")
+	result := RunFile(path)
+	for _, step := range result.Steps {
+		if step.Failure != "" {
+			t.Fatalf("step %v failed: %v", step.Name, step.Failure)
+		}
+	}
+
+	report := junit(t, result)
+	if report.Tests != 5 || report.Failures != 0 || report.Skipped != 0 {
+		t.Fatalf("expected 5 passed tests, got %v tests, %v failures, %v skipped", report.Tests, report.Failures, report.Skipped)
+	}
+	suite := report.Suites[0]
+	if suite.Name != "sum" || suite.File != path {
+		t.Fatalf("unexpected suite %v of %v", suite.Name, suite.File)
+	}
+	if name := suite.Cases[2].Name; name != "3 deploy sum.wasm" {
+		t.Fatalf("unexpected test case %v", name)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	path := scenarioDir(t, "sum.yaml")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Steps[2].Expect.Storage[0].Value = "{}"
+	result := Run(s, filepath.Dir(path))
+	if !result.Failed() {
+		t.Fatal("scenario with a wrong expectation must fail")
+	}
+
+	report := junit(t, result, RunFile(filepath.Join(filepath.Dir(path), "missing.yaml")))
+	if report.Tests != 6 || report.Failures != 2 || report.Skipped != 2 {
+		t.Fatalf("expected 6 tests, 2 failures, 2 skipped, got %v, %v, %v", report.Tests, report.Failures, report.Skipped)
+	}
+	failed := report.Suites[0].Cases[2]
+	if failed.Failure == nil || failed.Failure.Message != `expected STATE of sum to be {}, got {"result":{"key":"cg=="}}` {
+		t.Fatalf("unexpected failure of %v: %+v", failed.Name, failed.Failure)
+	}
+	if report.Suites[0].Cases[3].Skipped == nil {
+		t.Fatal("steps after the failed one must be skipped")
+	}
+	if load := report.Suites[1].Cases[0]; load.Name != "load" || load.Failure == nil {
+		t.Fatalf("expected a failed load step, got %+v", load)
+	}
+}
@@ -0,0 +1,186 @@
+// Package scenario runs declarative contract scenarios against an in-process chain.
+//
+// A scenario is a YAML or JSON file with a list of steps, every step has a single action and optional expectations:
+//
+//	name: counter
+//	steps:
+//	  - createAccount: {name: alice, balance: "100"}
+//	  - deploy: {name: counter, from: alice, file: counter.wasm}
+//	    expect: {success: true}
+//	  - call: {from: alice, contract: counter, method: inc, args: [{format: uint64, value: "2"}]}
+//	    expect:
+//	      events: [{name: changed}]
+//	      storage: [{contract: counter, key: STATE, format: uint64, value: "2"}]
+//	      balanceDeltas: [{account: counter, delta: "0"}]
+//	  - mine: 2
+//	  - advanceTime: 3600
+//	  - read: {contract: counter, method: get, format: uint64}
+//	    expect: {value: "2"}
+//
+// Accounts and contracts are referenced by their names, by hex addresses or as god. Wasm files are resolved relative
+// to the scenario file. testdata/sum.yaml is a complete scenario of the sum contract of the idena-wasm-binding tests.
+package scenario
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+type Scenario struct {
+	Name  string  `json:"name" yaml:"name"`
+	Steps []*Step `json:"steps" yaml:"steps"`
+}
+
+// Step has exactly one action
+type Step struct {
+	Name          string         `json:"name" yaml:"name"`
+	CreateAccount *CreateAccount `json:"createAccount" yaml:"createAccount"`
+	SetBalance    *SetBalance    `json:"setBalance" yaml:"setBalance"`
+	Deploy        *Deploy        `json:"deploy" yaml:"deploy"`
+	Call          *Call          `json:"call" yaml:"call"`
+	Mine          int            `json:"mine" yaml:"mine"`
+	// AdvanceTime mines a block with the time moved forward by the seconds
+	AdvanceTime int64   `json:"advanceTime" yaml:"advanceTime"`
+	Read        *Read   `json:"read" yaml:"read"`
+	Expect      *Expect `json:"expect" yaml:"expect"`
+}
+
+type CreateAccount struct {
+	Name    string `json:"name" yaml:"name"`
+	Balance string `json:"balance" yaml:"balance"`
+}
+
+type SetBalance struct {
+	Account string `json:"account" yaml:"account"`
+	Balance string `json:"balance" yaml:"balance"`
+}
+
+// Arg is a contract argument, arguments are indexed by their position. Address values may be names.
+type Arg struct {
+	Format string `json:"format" yaml:"format"`
+	Value  string `json:"value" yaml:"value"`
+}
+
+type Deploy struct {
+	// Name references the deployed contract in the following steps
+	Name   string `json:"name" yaml:"name"`
+	From   string `json:"from" yaml:"from"`
+	File   string `json:"file" yaml:"file"`
+	Amount string `json:"amount" yaml:"amount"`
+	MaxFee string `json:"maxFee" yaml:"maxFee"`
+	Args   []Arg  `json:"args" yaml:"args"`
+}
+
+type Call struct {
+	From     string `json:"from" yaml:"from"`
+	Contract string `json:"contract" yaml:"contract"`
+	Method   string `json:"method" yaml:"method"`
+	Amount   string `json:"amount" yaml:"amount"`
+	MaxFee   string `json:"maxFee" yaml:"maxFee"`
+	Args     []Arg  `json:"args" yaml:"args"`
+}
+
+type Read struct {
+	Contract string `json:"contract" yaml:"contract"`
+	Method   string `json:"method" yaml:"method"`
+	Format   string `json:"format" yaml:"format"`
+	Args     []Arg  `json:"args" yaml:"args"`
+}
+
+type Expect struct {
+	// Success is checked against the receipt of deploy and call steps and against the error of read steps
+	Success *bool `json:"success" yaml:"success"`
+	// Error is a substring of the receipt error or of the error which rejected the tx
+	Error string `json:"error" yaml:"error"`
+	// Value is the result of a read step, composite values are compared in their JSON form
+	Value         *string           `json:"value" yaml:"value"`
+	Events        []ExpectedEvent   `json:"events" yaml:"events"`
+	Storage       []ExpectedStorage `json:"storage" yaml:"storage"`
+	BalanceDeltas []ExpectedBalance `json:"balanceDeltas" yaml:"balanceDeltas"`
+}
+
+type ExpectedEvent struct {
+	Name string `json:"name" yaml:"name"`
+	// Contract is the receipt contract if empty
+	Contract string `json:"contract" yaml:"contract"`
+}
+
+type ExpectedStorage struct {
+	Contract string `json:"contract" yaml:"contract"`
+	Key      string `json:"key" yaml:"key"`
+	Format   string `json:"format" yaml:"format"`
+	Value    string `json:"value" yaml:"value"`
+}
+
+// ExpectedBalance is a balance change of the account made by the step
+type ExpectedBalance struct {
+	Account string `json:"account" yaml:"account"`
+	Delta   string `json:"delta" yaml:"delta"`
+}
+
+// Load reads the scenario file, files with the .json extension are decoded as JSON and others as YAML
+func Load(path string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Scenario
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &s)
+	} else {
+		err = yaml.Unmarshal(data, &s)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %v", path)
+	}
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	for i, step := range s.Steps {
+		if step == nil {
+			return nil, errors.Errorf("%v: step %v is empty", path, i+1)
+		}
+		if n := step.actions(); n != 1 {
+			return nil, errors.Errorf("%v: step %v has %v actions, expected one", path, i+1, n)
+		}
+	}
+	return &s, nil
+}
+
+func (s *Step) actions() int {
+	n := 0
+	for _, set := range []bool{s.CreateAccount != nil, s.SetBalance != nil, s.Deploy != nil, s.Call != nil, s.Mine > 0,
+		s.AdvanceTime > 0, s.Read != nil} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// title describes the step in reports
+func (s *Step) title() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	switch {
+	case s.CreateAccount != nil:
+		return "createAccount " + s.CreateAccount.Name
+	case s.SetBalance != nil:
+		return "setBalance " + s.SetBalance.Account
+	case s.Deploy != nil:
+		return "deploy " + s.Deploy.File
+	case s.Call != nil:
+		return "call " + s.Call.Contract + "." + s.Call.Method
+	case s.Mine > 0:
+		return "mine"
+	case s.AdvanceTime > 0:
+		return "advanceTime"
+	default:
+		return "read " + s.Read.Contract + "." + s.Read.Method
+	}
+}
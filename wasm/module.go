@@ -0,0 +1,354 @@
+package wasm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/pkg/errors"
+)
+
+var magic = []byte{0x00, 0x61, 0x73, 0x6d}
+
+const (
+	sectionCustom   = 0
+	sectionImport   = 2
+	sectionFunction = 3
+	sectionExport   = 7
+	sectionCode     = 10
+
+	nameSubsectionFunction = 1
+)
+
+type ExternalKind byte
+
+const (
+	KindFunction ExternalKind = 0
+	KindTable    ExternalKind = 1
+	KindMemory   ExternalKind = 2
+	KindGlobal   ExternalKind = 3
+)
+
+func (k ExternalKind) String() string {
+	switch k {
+	case KindFunction:
+		return "function"
+	case KindTable:
+		return "table"
+	case KindMemory:
+		return "memory"
+	case KindGlobal:
+		return "global"
+	default:
+		return "unknown"
+	}
+}
+
+type Import struct {
+	Module string
+	Name   string
+	Kind   ExternalKind
+}
+
+type Export struct {
+	Name  string
+	Kind  ExternalKind
+	Index uint32
+}
+
+type Function struct {
+	// Index in the function index space, imported functions come first
+	Index uint32
+	Name  string
+	// Size of the function body in bytes, zero for imported functions
+	BodySize uint32
+	Imported bool
+}
+
+type Module struct {
+	Imports   []Import
+	Exports   []Export
+	Functions []*Function
+}
+
+// ParseModule reads the sections of a wasm binary that are needed to inspect its interface: imports, exports,
+// function bodies and the function names from the "name" custom section. Everything else is skipped.
+func ParseModule(code []byte) (*Module, error) {
+	if len(code) < 8 || !bytes.Equal(code[:4], magic) {
+		return nil, errors.New("not a wasm module")
+	}
+	r := &reader{data: code, pos: 8}
+	m := &Module{}
+	var importedFuncs uint32
+	var funcNames map[uint32]string
+	for r.pos < len(r.data) {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		if r.pos+int(size) > len(r.data) {
+			return nil, errors.Errorf("section %v is truncated", id)
+		}
+		section := &reader{data: r.data[r.pos : r.pos+int(size)]}
+		r.pos += int(size)
+
+		switch id {
+		case sectionImport:
+			if err := parseImports(section, m); err != nil {
+				return nil, errors.Wrap(err, "import section")
+			}
+			for _, imp := range m.Imports {
+				if imp.Kind == KindFunction {
+					m.Functions = append(m.Functions, &Function{Index: importedFuncs, Name: imp.Module + "." + imp.Name, Imported: true})
+					importedFuncs++
+				}
+			}
+		case sectionFunction:
+			if err := parseFunctions(section, m, importedFuncs); err != nil {
+				return nil, errors.Wrap(err, "function section")
+			}
+		case sectionExport:
+			if err := parseExports(section, m); err != nil {
+				return nil, errors.Wrap(err, "export section")
+			}
+		case sectionCode:
+			if err := parseCode(section, m, importedFuncs); err != nil {
+				return nil, errors.Wrap(err, "code section")
+			}
+		case sectionCustom:
+			name, err := section.name()
+			if err != nil {
+				return nil, errors.Wrap(err, "custom section")
+			}
+			if name == "name" {
+				// the name section is optional debug info, a malformed one should not make the module unreadable
+				funcNames, _ = parseFunctionNames(section)
+			}
+		}
+	}
+	for _, f := range m.Functions {
+		if name, ok := funcNames[f.Index]; ok {
+			f.Name = name
+		}
+	}
+	for _, e := range m.Exports {
+		if e.Kind == KindFunction && int(e.Index) < len(m.Functions) && m.Functions[e.Index].Name == "" {
+			m.Functions[e.Index].Name = e.Name
+		}
+	}
+	return m, nil
+}
+
+// ExportedFunctions returns the names of the exported functions, i.e. the methods that can be called by a transaction.
+func (m *Module) ExportedFunctions() []string {
+	var result []string
+	for _, e := range m.Exports {
+		if e.Kind == KindFunction {
+			result = append(result, e.Name)
+		}
+	}
+	return result
+}
+
+func parseImports(r *reader, m *Module) error {
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		module, err := r.name()
+		if err != nil {
+			return err
+		}
+		name, err := r.name()
+		if err != nil {
+			return err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if err := r.skipImportDesc(ExternalKind(kind)); err != nil {
+			return err
+		}
+		m.Imports = append(m.Imports, Import{Module: module, Name: name, Kind: ExternalKind(kind)})
+	}
+	return nil
+}
+
+func parseFunctions(r *reader, m *Module, importedFuncs uint32) error {
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		// the type index of the function
+		if _, err := r.u32(); err != nil {
+			return err
+		}
+		m.Functions = append(m.Functions, &Function{Index: importedFuncs + i})
+	}
+	return nil
+}
+
+func parseExports(r *reader, m *Module) error {
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		name, err := r.name()
+		if err != nil {
+			return err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return err
+		}
+		idx, err := r.u32()
+		if err != nil {
+			return err
+		}
+		m.Exports = append(m.Exports, Export{Name: name, Kind: ExternalKind(kind), Index: idx})
+	}
+	return nil
+}
+
+func parseCode(r *reader, m *Module, importedFuncs uint32) error {
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		size, err := r.u32()
+		if err != nil {
+			return err
+		}
+		if err := r.skip(int(size)); err != nil {
+			return err
+		}
+		idx := importedFuncs + i
+		if int(idx) < len(m.Functions) {
+			m.Functions[idx].BodySize = size
+		}
+	}
+	return nil
+}
+
+func parseFunctionNames(r *reader) (map[uint32]string, error) {
+	names := make(map[uint32]string)
+	for r.pos < len(r.data) {
+		id, err := r.byte()
+		if err != nil {
+			return names, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return names, err
+		}
+		if id != nameSubsectionFunction {
+			if err := r.skip(int(size)); err != nil {
+				return names, err
+			}
+			continue
+		}
+		count, err := r.u32()
+		if err != nil {
+			return names, err
+		}
+		for i := uint32(0); i < count; i++ {
+			idx, err := r.u32()
+			if err != nil {
+				return names, err
+			}
+			name, err := r.name()
+			if err != nil {
+				return names, err
+			}
+			names[idx] = name
+		}
+	}
+	return names, nil
+}
+
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, errors.New("unexpected end of data")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *reader) u32() (uint32, error) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 || v > 0xFFFFFFFF {
+		return 0, errors.New("invalid LEB128 value")
+	}
+	r.pos += n
+	return uint32(v), nil
+}
+
+func (r *reader) skip(n int) error {
+	if n < 0 || r.pos+n > len(r.data) {
+		return errors.New("unexpected end of data")
+	}
+	r.pos += n
+	return nil
+}
+
+func (r *reader) name() (string, error) {
+	size, err := r.u32()
+	if err != nil {
+		return "", err
+	}
+	start := r.pos
+	if err := r.skip(int(size)); err != nil {
+		return "", err
+	}
+	return string(r.data[start:r.pos]), nil
+}
+
+func (r *reader) skipLimits() error {
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if _, err := r.u32(); err != nil {
+		return err
+	}
+	if flags&0x01 != 0 {
+		_, err = r.u32()
+	}
+	return err
+}
+
+func (r *reader) skipImportDesc(kind ExternalKind) error {
+	switch kind {
+	case KindFunction:
+		_, err := r.u32()
+		return err
+	case KindTable:
+		if _, err := r.byte(); err != nil {
+			return err
+		}
+		return r.skipLimits()
+	case KindMemory:
+		return r.skipLimits()
+	case KindGlobal:
+		if _, err := r.byte(); err != nil {
+			return err
+		}
+		_, err := r.byte()
+		return err
+	default:
+		return errors.Errorf("unknown import kind %d", byte(kind))
+	}
+}
@@ -0,0 +1,172 @@
+package wasm
+
+import (
+	"github.com/idena-network/idena-wasm-binding/tests/testdata"
+	"reflect"
+	"testing"
+)
+
+var header = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+func section(id byte, content ...byte) []byte {
+	return append([]byte{id, byte(len(content))}, content...)
+}
+
+func name(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var result []byte
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+	return result
+}
+
+func module(sections ...[]byte) []byte {
+	return concat(append([][]byte{header}, sections...)...)
+}
+
+// testModule imports a function and a memory, defines two functions, exports the first one and names both
+func testModule() (code []byte, sectionEnds []int) {
+	sections := [][]byte{
+		section(1, 0x01, 0x60, 0x00, 0x00),
+		section(2, concat([]byte{0x02},
+			name("env"), name("log"), []byte{byte(KindFunction), 0x00},
+			name("env"), name("mem"), []byte{byte(KindMemory), 0x00, 0x01})...),
+		section(3, 0x02, 0x00, 0x00),
+		section(7, concat([]byte{0x02},
+			name("run"), []byte{byte(KindFunction), 0x01},
+			name("memory"), []byte{byte(KindMemory), 0x00})...),
+		section(10, 0x02, 0x02, 0x00, 0x0b, 0x03, 0x00, 0x01, 0x0b),
+		section(0, concat(name("name"), []byte{nameSubsectionFunction, 0x10, 0x02}, []byte{0x01}, name("run_impl"), []byte{0x02}, name("inc"))...),
+	}
+	code = header
+	sectionEnds = []int{len(code)}
+	for _, s := range sections {
+		code = append(code, s...)
+		sectionEnds = append(sectionEnds, len(code))
+	}
+	return code, sectionEnds
+}
+
+func TestParseModule(t *testing.T) {
+	code, _ := testModule()
+	m, err := ParseModule(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imports := []Import{{"env", "log", KindFunction}, {"env", "mem", KindMemory}}
+	if !reflect.DeepEqual(m.Imports, imports) {
+		t.Fatalf("expected imports %+v, got %+v", imports, m.Imports)
+	}
+	exports := []Export{{"run", KindFunction, 1}, {"memory", KindMemory, 0}}
+	if !reflect.DeepEqual(m.Exports, exports) {
+		t.Fatalf("expected exports %+v, got %+v", exports, m.Exports)
+	}
+	functions := []Function{
+		{Index: 0, Name: "env.log", Imported: true},
+		{Index: 1, Name: "run_impl", BodySize: 2},
+		{Index: 2, Name: "inc", BodySize: 3},
+	}
+	if len(m.Functions) != len(functions) {
+		t.Fatalf("expected %v functions, got %v", len(functions), len(m.Functions))
+	}
+	for i, f := range m.Functions {
+		if *f != functions[i] {
+			t.Fatalf("expected function %+v, got %+v", functions[i], *f)
+		}
+	}
+	if names := m.ExportedFunctions(); !reflect.DeepEqual(names, []string{"run"}) {
+		t.Fatalf("unexpected exported functions %v", names)
+	}
+}
+
+func TestParseContract(t *testing.T) {
+	code, err := testdata.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ParseModule(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported := make(map[string]bool)
+	for _, name := range m.ExportedFunctions() {
+		exported[name] = true
+	}
+	if !exported["deploy"] || !exported["compute"] {
+		t.Fatalf("expected deploy and compute exports, got %v", m.ExportedFunctions())
+	}
+}
+
+// TestParseTruncated cuts the module at every byte, modules cut between sections stay readable
+func TestParseTruncated(t *testing.T) {
+	code, sectionEnds := testModule()
+	boundaries := make(map[int]bool)
+	for _, end := range sectionEnds {
+		boundaries[end] = true
+	}
+	for n := 0; n < len(code); n++ {
+		_, err := ParseModule(code[:n])
+		if boundaries[n] && err != nil {
+			t.Fatalf("module cut after a section at %v: %v", n, err)
+		}
+		if !boundaries[n] && err == nil {
+			t.Fatalf("module cut at %v must fail", n)
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	cases := []struct {
+		name string
+		code []byte
+		err  string
+	}{
+		{"empty", nil, "not a wasm module"},
+		{"magic", []byte{0x00, 0x61, 0x73, 0x6e, 0x01, 0x00, 0x00, 0x00}, "not a wasm module"},
+		{"section size", module([]byte{3, 0xff}), "invalid LEB128 value"},
+		{"section size overflow", module([]byte{3, 0xff, 0xff, 0xff, 0xff, 0x7f}), "invalid LEB128 value"},
+		{"function count", module(section(3, 0xff, 0xff, 0xff, 0xff, 0x0f)), "function section: invalid LEB128 value"},
+		{"import kind", module(section(2, concat([]byte{0x01}, name("env"), name("x"), []byte{0x05})...)), "import section: unknown import kind 5"},
+		{"import limits", module(section(2, concat([]byte{0x01}, name("env"), name("mem"), []byte{byte(KindMemory), 0x01, 0x01})...)), "import section: invalid LEB128 value"},
+		{"export name", module(section(7, 0x01, 0x0a, 'r')), "export section: unexpected end of data"},
+		{"export count", module(section(7, 0x02, 0x01, 'r', 0x00, 0x00)), "export section: invalid LEB128 value"},
+		{"code body", module(section(3, 0x01, 0x00), section(10, 0x01, 0x05, 0x00)), "code section: unexpected end of data"},
+		{"custom name", module(section(0, 0x05, 'n')), "custom section: unexpected end of data"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseModule(c.code)
+			if err == nil || err.Error() != c.err {
+				t.Fatalf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestParseLenient(t *testing.T) {
+	cases := []struct {
+		name      string
+		code      []byte
+		functions int
+	}{
+		// the name section is debug info, a malformed one is ignored
+		{"name section", module(section(3, 0x01, 0x00), section(0, concat(name("name"), []byte{nameSubsectionFunction, 0x05, 0x01, 0x00, 0x09})...)), 1},
+		{"code without functions", module(section(10, 0x01, 0x02, 0x00, 0x0b)), 0},
+		{"export out of functions", module(section(7, concat([]byte{0x01}, name("run"), []byte{byte(KindFunction), 0x07})...)), 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m, err := ParseModule(c.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(m.Functions) != c.functions {
+				t.Fatalf("expected %v functions, got %v", c.functions, len(m.Functions))
+			}
+		})
+	}
+}